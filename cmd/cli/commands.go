@@ -0,0 +1,517 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/excelapp"
+	"vocabulary/internal/storage"
+	"vocabulary/internal/transfer"
+)
+
+// A maintenance subcommand of the storage database.
+type command struct {
+	usage       string
+	description string
+
+	//Defines flags of the command in fs. Returns the function which runs
+	//the command with positional arguments after parsing of flags.
+	define func(fs *flag.FlagSet) func(ctx context.Context, file *storage.File, args []string) error
+}
+
+var commands = map[string]command{
+	"lessons": {
+		description: "list stored lessons of the profile, the most recently used first",
+		define:      defineLessons,
+	},
+	"profiles": {
+		description: "list profiles of learners, the most recently selected first",
+		define:      defineProfiles,
+	},
+	"stats": {
+		usage:       "<file> <sheet>",
+		description: "show stored statistics of phrases of the lesson",
+		define:      defineStats,
+	},
+	"sessions": {
+		usage:       "<file> <sheet>",
+		description: "list logged sessions of the lesson with their' seeds (see -seed flag of lessons) and starting states",
+		define:      defineSessions,
+	},
+	"reset": {
+		usage:       "<file> <sheet> [phrase]",
+		description: "remove stored progress of the lesson or of one phrase of it (shared knowledge of phrases is kept)",
+		define:      defineReset,
+	},
+	"prune": {
+		description: "remove data of lessons which are outdated and exceed the limit (logs of answers are kept)",
+		define:      definePrune,
+	},
+	"export": {
+		usage:       "[output]",
+		description: "export stored progress of lessons to a JSON or CSV file (standard output by default)",
+		define:      defineExport,
+	},
+	"import": {
+		usage:       "<input>",
+		description: "merge progress from a JSON or CSV file into the storage",
+		define:      defineImport,
+	},
+	"merge": {
+		usage:       "<other storage>",
+		description: "merge progress of lessons from another storage file",
+		define:      defineMerge,
+	},
+	"vacuum": {
+		description: "return space of removed data to the file system",
+		define:      defineVacuum,
+	},
+	"check": {
+		description: "check integrity of the storage database",
+		define:      defineCheck,
+	},
+}
+
+func printCommands(out io.Writer) {
+	fmt.Fprintf(out, "Commands (run \"%s <command> -h\" for flags):\n", os.Args[0])
+
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		fmt.Fprintf(out, "  %s %s\n    \t%s\n", name, commands[name].usage, commands[name].description)
+	}
+}
+
+// Runs the command with its' arguments (without the name of the command).
+func runCommand(name string, args []string) error {
+	var (
+		cmd         = commands[name]
+		fs          = flag.NewFlagSet(name, flag.ExitOnError)
+		storagePath = fs.String("storage", excelapp.STORAGE_FILE_PATH, "custom storage file path")
+		profile     = fs.String("profile", "", "profile of the learner (the last selected one by default)")
+		run         = cmd.define(fs)
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] %s\n%s\n", os.Args[0], name, cmd.usage, cmd.description)
+
+		fs.PrintDefaults()
+	}
+
+	fs.Parse(args)
+
+	file, err := storage.Open(context.Background(), *storagePath)
+
+	if err != nil {
+		return err
+	}
+
+	if *profile != "" {
+		err = file.UseProfile(context.Background(), *profile)
+
+		if errors.Is(err, storage.ErrWasNotSaved) {
+			err = fmt.Errorf("unknown profile %q", *profile)
+		}
+
+		if err != nil {
+			return errors.Join(err, file.Close())
+		}
+	}
+
+	return errors.Join(run(context.Background(), file, fs.Args()), file.Close())
+}
+
+func modeName(mode app.LessonMode) string {
+	switch mode {
+	case app.LessonModeLern:
+		return MODE_LEARN
+	case app.LessonModeLeanSpellingOnly:
+		return MODE_SPELLING
+	case app.LessonModeSpacedRepetition:
+		return MODE_SPACED_REPETITION
+	}
+
+	return fmt.Sprint(mode)
+}
+
+func defineLessons(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, _ []string) error {
+		lessons, err := file.ListLessons(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "LAST USED\tMODE\tPROGRESS\tSCHEDULE\tFILE\tSHEET")
+
+		for _, lesson := range lessons {
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%d\t%d\t%s\t%s\n",
+				lesson.LastUTC.Local().Format(time.DateTime),
+				modeName(lesson.Mode),
+				lesson.PhrasesWithProgress,
+				lesson.PhrasesWithSchedule,
+				lesson.FilePath,
+				lesson.Sheet,
+			)
+		}
+
+		return w.Flush()
+	}
+}
+
+func defineProfiles(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, _ []string) error {
+		profiles, err := file.Profiles(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			if profile == file.Profile() {
+				fmt.Println("*", profile)
+			} else {
+				fmt.Println(" ", profile)
+			}
+		}
+
+		return nil
+	}
+}
+
+func defineStats(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, args []string) error {
+		if len(args) != 2 {
+			return errors.New("expected the file and the sheet of the lesson")
+		}
+
+		statisticsByPhrase, err := file.LoadLessonProgress(ctx, args[0], args[1])
+
+		if err != nil && !errors.Is(err, storage.ErrWasNotSaved) {
+			return err
+		}
+
+		scheduleByPhrase, err := file.LoadLessonSchedule(ctx, args[0], args[1])
+
+		if err != nil && !errors.Is(err, storage.ErrWasNotSaved) {
+			return err
+		}
+
+		if len(statisticsByPhrase) <= 0 && len(scheduleByPhrase) <= 0 {
+			return fmt.Errorf("no progress of %q, %q is stored", args[0], args[1])
+		}
+
+		keys := slices.Concat(slices.Collect(maps.Keys(statisticsByPhrase)), slices.Collect(maps.Keys(scheduleByPhrase)))
+
+		slices.SortFunc(
+			keys,
+			func(a, b app.PhraseKey) int {
+				return cmp.Or(cmp.Compare(a.Phrase, b.Phrase), cmp.Compare(a.Occurrence, b.Occurrence))
+			},
+		)
+
+		keys = slices.Compact(keys)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		//Counters are "right/failed" for choice of options and "right/almost/failed" for typing.
+		fmt.Fprintln(w, "PHRASE\tOPTIONS\tOPTIONS INVERTED\tTYPING\tTYPING INVERTED\tREVIEWS\tLAPSES\tDUE")
+
+		for _, key := range keys {
+			var (
+				stats    = statisticsByPhrase[key]
+				schedule = scheduleByPhrase[key]
+				phrase   = key.Phrase
+				due      string
+			)
+
+			if key.Occurrence > 0 {
+				phrase = fmt.Sprintf("%s (#%d)", phrase, key.Occurrence+1)
+			}
+
+			if !schedule.Due.IsZero() {
+				due = schedule.Due.Local().Format(time.DateTime)
+			}
+
+			fmt.Fprintf(
+				w,
+				"%s\t%d/%d\t%d/%d\t%d/%d/%d\t%d/%d/%d\t%d\t%d\t%s\n",
+				phrase,
+				stats.CountGuessedOOS, stats.CountFailedOOS,
+				stats.CountGuessedOOSInverted, stats.CountFailedOOSInverted,
+				stats.CountAnsweredTM, stats.CountAlmostTM, stats.CountFailedTM,
+				stats.CountAnsweredTMInverted, stats.CountAlmostTMInverted, stats.CountFailedTMInverted,
+				schedule.Reviews,
+				schedule.Lapses,
+				due,
+			)
+		}
+
+		return w.Flush()
+	}
+}
+
+func defineSessions(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, args []string) error {
+		if len(args) != 2 {
+			return errors.New("expected the file and the sheet of the lesson")
+		}
+
+		sessions, err := file.LoadSessions(ctx, args[0], args[1])
+
+		if err != nil {
+			return err
+		}
+
+		if len(sessions) <= 0 {
+			return fmt.Errorf("no sessions of %q, %q are logged", args[0], args[1])
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		//Sessions with the same seed and state have the same tasks.
+		fmt.Fprintln(w, "STARTED\tMODE\tSEED\tRECOVERED\tSTATE")
+
+		for _, session := range sessions {
+			state := session.StateHash
+
+			if len(state) > STATE_HASH_LENGTH {
+				state = state[:STATE_HASH_LENGTH]
+			}
+
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%d\t%t\t%s\n",
+				session.StartUTC.Local().Format(time.DateTime),
+				modeName(session.Mode),
+				session.Seed,
+				session.ProgressRecovered,
+				state,
+			)
+		}
+
+		return w.Flush()
+	}
+}
+
+func defineReset(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, args []string) error {
+		if len(args) != 2 && len(args) != 3 {
+			return errors.New("expected the file and the sheet of the lesson and optionally the phrase")
+		}
+
+		phrase := ""
+
+		if len(args) == 3 {
+			phrase = args[2]
+		}
+
+		err := file.ResetProgress(ctx, args[0], args[1], phrase)
+
+		if errors.Is(err, storage.ErrWasNotSaved) {
+			return errors.New("no progress to reset is stored")
+		}
+
+		return err
+	}
+}
+
+func definePrune(fs *flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	var (
+		maxLessons = fs.Uint("max-lessons", excelapp.MAX_LESSONS_COUNT_TO_STORE_PROGRESS, "count of the most recently used lessons which are always kept")
+		olderThan  = fs.Duration("older-than", excelapp.TIME_TO_STORE_LESSONS_PROGRESS, "lessons over the limit are removed if they weren't used during this period")
+	)
+
+	return func(ctx context.Context, file *storage.File, _ []string) error {
+		return file.EraseOutdatedData(ctx, uint32(*maxLessons), time.Now().Add(*olderThan*-1))
+	}
+}
+
+func defineVacuum(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, _ []string) error {
+		return file.Vacuum(ctx)
+	}
+}
+
+func defineCheck(*flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	return func(ctx context.Context, file *storage.File, _ []string) error {
+		problems, err := file.CheckIntegrity(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("%d problems found", len(problems))
+		}
+
+		fmt.Println("ok")
+
+		return nil
+	}
+}
+
+func defineExport(fs *flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	var (
+		excelFilePath = fs.String("file", "", "export lessons of this file only")
+		sheet         = fs.String("sheet", "", "export lessons of this sheet only")
+		format        = fs.String("format", "", "format of output: "+transfer.FORMAT_JSON+" or "+transfer.FORMAT_CSV+" (by extension of output by default)")
+	)
+
+	return func(ctx context.Context, file *storage.File, args []string) error {
+		if len(args) > 1 {
+			return errors.New("expected only the output file")
+		}
+
+		records, err := file.ExportProgress(ctx, *excelFilePath, *sheet)
+
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			return transfer.Write(os.Stdout, cmp.Or(*format, transfer.FORMAT_JSON), records)
+		}
+
+		output, err := os.Create(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		err = transfer.Write(output, cmp.Or(*format, transfer.FormatOf(args[0])), records)
+
+		return errors.Join(err, output.Close())
+	}
+}
+
+func defineImport(fs *flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	var (
+		format     = fs.String("format", "", "format of input: "+transfer.FORMAT_JSON+" or "+transfer.FORMAT_CSV+" (by extension of input by default)")
+		policyName = fs.String("policy", POLICY_MAX, "merging of stored and imported counters of a phrase: "+POLICY_MAX+", "+POLICY_SUM+" or "+POLICY_OVERWRITE)
+	)
+
+	return func(ctx context.Context, file *storage.File, args []string) error {
+		if len(args) != 1 {
+			return errors.New("expected the input file")
+		}
+
+		policy, err := conflictPolicy(*policyName)
+
+		if err != nil {
+			return err
+		}
+
+		input, err := os.Open(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		defer input.Close()
+
+		records, err := transfer.Read(input, cmp.Or(*format, transfer.FormatOf(args[0])))
+
+		if err != nil {
+			return err
+		}
+
+		added, merged, err := file.ImportProgress(ctx, records, policy)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d phrases added, %d merged\n", added, merged)
+
+		return nil
+	}
+}
+
+func conflictPolicy(name string) (storage.ConflictPolicy, error) {
+	switch name {
+	case POLICY_MAX:
+		return storage.ConflictKeepMax, nil
+	case POLICY_SUM:
+		return storage.ConflictSum, nil
+	case POLICY_OVERWRITE:
+		return storage.ConflictOverwrite, nil
+	}
+
+	return 0, fmt.Errorf("unknown merging policy %q", name)
+}
+
+func defineMerge(fs *flag.FlagSet) func(context.Context, *storage.File, []string) error {
+	var (
+		policyName = fs.String("policy", POLICY_MAX, "merging of counters of a phrase stored in both files: "+POLICY_MAX+", "+POLICY_SUM+" or "+POLICY_OVERWRITE)
+		dryRun     = fs.Bool("dry-run", false, "only report what would be merged")
+	)
+
+	return func(ctx context.Context, file *storage.File, args []string) error {
+		if len(args) != 1 {
+			return errors.New("expected the other storage file")
+		}
+
+		policy, err := conflictPolicy(*policyName)
+
+		if err != nil {
+			return err
+		}
+
+		mergedLessons, err := file.Merge(ctx, args[0], policy, *dryRun)
+
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "FILE\tSHEET\tMATCHED BY\tTARGET FILE\tTARGET SHEET\tADDED\tMERGED")
+
+		for _, lesson := range mergedLessons {
+			matchedBy := "-"
+
+			switch lesson.Match {
+			case storage.LessonMatchPath:
+				matchedBy = "path"
+			case storage.LessonMatchFingerprint:
+				matchedBy = "fingerprint"
+			}
+
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+				lesson.FilePath,
+				lesson.Sheet,
+				matchedBy,
+				lesson.TargetFilePath,
+				lesson.TargetSheet,
+				lesson.Added,
+				lesson.Merged,
+			)
+		}
+
+		err = w.Flush()
+
+		if err == nil && *dryRun {
+			fmt.Println("Dry run: nothing was changed")
+		}
+
+		return err
+	}
+}
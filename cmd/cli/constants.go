@@ -0,0 +1,26 @@
+package main
+
+// Values of "mode" flag.
+const (
+	MODE_LEARN             = "learn"
+	MODE_SPELLING          = "spelling"
+	MODE_SPACED_REPETITION = "repetition"
+)
+
+// Values of "policy" flag of import.
+const (
+	POLICY_MAX       = "max"
+	POLICY_SUM       = "sum"
+	POLICY_OVERWRITE = "overwrite"
+)
+
+const (
+	//The answer which reveals the right one (a hint first for typed answers).
+	REVEAL_ANSWER = "?"
+
+	//Count of first runes of the right translation shown as a hint.
+	HINT_LENGTH = 1
+
+	//Count of shown first characters of hashes of starting states of sessions.
+	STATE_HASH_LENGTH = 12
+)
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/excelapp"
+	"vocabulary/internal/storage"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		if _, found := commands[os.Args[1]]; found {
+			err := runCommand(os.Args[1], os.Args[2:])
+
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			return
+		}
+	}
+
+	//Errors are returned by run, so deferred calls store progress before the exit.
+	err := run()
+
+	if err != nil {
+		log.Print(err)
+
+		os.Exit(1)
+	}
+}
+
+// Runs a lesson in the terminal.
+func run() error {
+	flags := excelapp.RegisterFlags(flag.CommandLine)
+	sheet := flag.String("sheet", "", "sheet of the file to learn (the last learned or the first one by default)")
+	modeName := flag.String("mode", "", "lesson mode: "+MODE_LEARN+", "+MODE_SPELLING+" or "+MODE_SPACED_REPETITION+" (the last used by default)")
+	restart := flag.Bool("restart", false, "don't recover saved progress of the lesson")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file.xlsx]\n   or: %s <command> [flags] [arguments]\n", os.Args[0], os.Args[0])
+
+		flag.PrintDefaults()
+
+		printCommands(flag.CommandLine.Output())
+	}
+
+	flag.Parse()
+
+	settings, err := flags.Settings()
+
+	if err != nil {
+		return err
+	}
+
+	storage, err := storage.Open(context.Background(), flags.StorageFilePath)
+
+	if err != nil {
+		return err
+	}
+
+	defer storage.Close()
+
+	if flags.Profile != "" {
+		err = storage.SelectProfile(context.Background(), flags.Profile)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	appImpl := excelapp.New(storage, settings)
+
+	defer appImpl.Exit()
+
+	err = openLesson(appImpl, *sheet, *modeName)
+
+	if err != nil {
+		return err
+	}
+
+	//Interruption stops the lesson, but progress is still stored.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	defer cancel()
+
+	terminal := newTerminal(ctx, os.Stdin, os.Stdout)
+
+	if filePath, sheet, found := appImpl.RelinkCandidate(); found {
+		relink, err := terminal.confirm(
+			ctx,
+			fmt.Sprintf("Progress of a lesson with the same phrases was found: %s, %s.\nWas the file moved? Move its progress to this one?", filePath, sheet),
+		)
+
+		//The input is over or the program is interrupted before the lesson.
+		if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if relink {
+			err = appImpl.Relink(filePath, sheet)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	lesson, err := appImpl.BeginLesson(!*restart && appImpl.ProgressRecoveryIsAvailable())
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s, %s. Type %q to see the right answer, Ctrl+D to finish.\n", appImpl.FilePath(), appImpl.Topic(), REVEAL_ANSWER)
+
+	err = terminal.runLesson(ctx, lesson)
+
+	storage.EraseOutdatedData(
+		context.Background(),
+		excelapp.MAX_LESSONS_COUNT_TO_STORE_PROGRESS,
+		time.Now().Add(excelapp.TIME_TO_STORE_LESSONS_PROGRESS*-1),
+	)
+
+	return err
+}
+
+// Opens the file, the sheet and the mode chosen by flags or the last open ones.
+func openLesson(appImpl *excelapp.LoadAllFile, sheet, modeName string) error {
+	if flag.NArg() == 1 {
+		if !appImpl.OpenFile(flag.Arg(0)) {
+			return fmt.Errorf("can't open %q", flag.Arg(0))
+		}
+	} else {
+		err := appImpl.OpenLast()
+
+		if err != nil {
+			return fmt.Errorf("pass the path of an Excel file: %w", err)
+		}
+
+		if appImpl.FilePath() == "" {
+			return errors.New("can't open the last file, pass the path of an Excel file")
+		}
+	}
+
+	if sheet != "" {
+		if !slices.Contains(appImpl.AvailableTopics(), sheet) {
+			return fmt.Errorf("no sheet %q in the file, available ones: %s", sheet, strings.Join(appImpl.AvailableTopics(), ", "))
+		}
+
+		appImpl.ChooseTopic(sheet)
+	}
+
+	switch modeName {
+	case "":
+	case MODE_LEARN:
+		appImpl.SetLessonMode(app.LessonModeLern)
+	case MODE_SPELLING:
+		appImpl.SetLessonMode(app.LessonModeLeanSpellingOnly)
+	case MODE_SPACED_REPETITION:
+		appImpl.SetLessonMode(app.LessonModeSpacedRepetition)
+	default:
+		return fmt.Errorf("unknown lesson mode %q", modeName)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"vocabulary/internal/app"
+)
+
+// Runs lessons in a terminal: options of choice tasks are numbered,
+// translations are typed, REVEAL_ANSWER shows the right answer.
+type terminal struct {
+	lines <-chan string
+	out   io.Writer
+}
+
+// Reads lines of in in background, so waiting for an answer can be interrupted by ctx.
+func newTerminal(ctx context.Context, in io.Reader, out io.Writer) *terminal {
+	lines := make(chan string)
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(in)
+
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &terminal{
+		lines: lines,
+		out:   out,
+	}
+}
+
+// Returns io.EOF when the input is over.
+func (t *terminal) readAnswer(ctx context.Context) (string, error) {
+	fmt.Fprint(t.out, "> ")
+
+	select {
+	case line, ok := <-t.lines:
+		if !ok {
+			return "", io.EOF
+		}
+
+		return strings.TrimSpace(line), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Asks a yes/no question, the answer is "no" by default.
+func (t *terminal) confirm(ctx context.Context, question string) (bool, error) {
+	fmt.Fprintf(t.out, "%s [y/N]\n", question)
+
+	answer, err := t.readAnswer(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"), nil
+}
+
+// Gives tasks of the lesson until the input is over or ctx is canceled.
+func (t *terminal) runLesson(ctx context.Context, lesson app.Lesson) error {
+	for {
+		task, err := lesson.Next(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(t.out, "\n%s\n", task.Phrase())
+
+		switch task := task.(type) {
+		case app.ChooseRightOption:
+			err = t.chooseRightOption(ctx, task)
+		case app.TranslateManually:
+			err = t.translateManually(ctx, task)
+		default:
+			err = fmt.Errorf("unsupported task %T", task)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+			fmt.Fprintln(t.out)
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (t *terminal) chooseRightOption(ctx context.Context, task app.ChooseRightOption) error {
+	options := task.Options()
+
+	for i, option := range options {
+		fmt.Fprintf(t.out, "%3d) %s\n", i+1, option)
+	}
+
+	for {
+		answer, err := t.readAnswer(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		if answer == REVEAL_ANSWER {
+			right, err := task.GetRightAnswer(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(t.out, "Right answer: %d) %s\n", right+1, options[right])
+
+			continue
+		}
+
+		number, err := strconv.Atoi(answer)
+
+		if err != nil || number < 1 || number > len(options) {
+			fmt.Fprintf(t.out, "Type the number of an option (from 1 to %d) or %q to see the right answer\n", len(options), REVEAL_ANSWER)
+
+			continue
+		}
+
+		isRight, err := task.Right(ctx, number-1)
+
+		if err != nil {
+			return err
+		}
+
+		if isRight {
+			fmt.Fprintln(t.out, "Right!")
+
+			return nil
+		}
+
+		fmt.Fprintln(t.out, "Wrong")
+	}
+}
+
+func (t *terminal) translateManually(ctx context.Context, task app.TranslateManually) error {
+	hintShown := false
+
+	for {
+		answer, err := t.readAnswer(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		if answer == "" {
+			continue
+		}
+
+		if answer == REVEAL_ANSWER {
+			right, err := task.GetRightAnswer(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			//The first request shows only the beginning of the translation,
+			//the same as the placeholder of the window does.
+			if !hintShown {
+				fmt.Fprintf(t.out, "Hint: %s...\n", string([]rune(right)[:min(HINT_LENGTH, len([]rune(right)))]))
+
+				hintShown = true
+			} else {
+				fmt.Fprintf(t.out, "Right answer: %s\n", right)
+			}
+
+			continue
+		}
+
+		verdict, err := task.Right(ctx, answer)
+
+		if err != nil {
+			return err
+		}
+
+		switch verdict {
+		case app.VerdictCorrect:
+			fmt.Fprintln(t.out, "Right!")
+
+			return nil
+		case app.VerdictAlmostCorrect:
+			fmt.Fprintf(t.out, "Almost right, check the typos: %s\n", task.RightTranslation())
+		default:
+			fmt.Fprintln(t.out, "Wrong")
+		}
+	}
+}
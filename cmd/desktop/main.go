@@ -5,16 +5,25 @@ import (
 	"flag"
 	"log"
 	"time"
+	"vocabulary/internal/excelapp"
 	"vocabulary/internal/storage"
 	"vocabulary/internal/ui"
 )
 
+var _ ui.Application = (*excelapp.LoadAllFile)(nil)
+
 func main() {
-	storageFilePath := flag.String("storage", STORAGE_FILE_PATH, "custom storage file path")
+	flags := excelapp.RegisterFlags(flag.CommandLine)
 
 	flag.Parse()
 
-	storage, err := storage.Open(context.Background(), *storageFilePath)
+	settings, err := flags.Settings()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	storage, err := storage.Open(context.Background(), flags.StorageFilePath)
 
 	if err != nil {
 		log.Fatal(err)
@@ -22,11 +31,17 @@ func main() {
 
 	defer storage.Close()
 
-	appImpl := &loadAllFile{
-		storage: storage,
+	if flags.Profile != "" {
+		err = storage.SelectProfile(context.Background(), flags.Profile)
+
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	defer appImpl.exit()
+	appImpl := excelapp.New(storage, settings)
+
+	defer appImpl.Exit()
 
 	if flag.NArg() == 1 {
 		appImpl.OpenFile(flag.Arg(0))
@@ -38,7 +53,7 @@ func main() {
 
 	storage.EraseOutdatedData(
 		context.Background(),
-		MAX_LESSONS_COUNT_TO_STORE_PROGRESS,
-		time.Now().Add(TIME_TO_STORE_LESSONS_PROGRESS*-1),
+		excelapp.MAX_LESSONS_COUNT_TO_STORE_PROGRESS,
+		time.Now().Add(excelapp.TIME_TO_STORE_LESSONS_PROGRESS*-1),
 	)
 }
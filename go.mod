@@ -6,6 +6,7 @@ require (
 	fyne.io/fyne/v2 v2.6.0
 	github.com/mattn/go-sqlite3 v1.14.28
 	github.com/xuri/excelize/v2 v2.9.0
+	golang.org/x/text v0.22.0
 )
 
 require (
@@ -46,6 +47,5 @@ require (
 	golang.org/x/image v0.24.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
-	golang.org/x/text v0.22.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,116 @@
+package advanced
+
+import (
+	"strings"
+	"vocabulary/internal/app"
+)
+
+// Returns the best verdict of checkAnswer() among all the accepted translations
+// and their forms without optional parts.
+func checkAnswerVariants(translation string, acceptedTranslations []string, n *normalizer) app.Verdict {
+	res := app.VerdictWrong
+
+	for _, acceptedTranslation := range acceptedTranslations {
+		for _, form := range withoutOptionalParts(acceptedTranslation) {
+			res = max(res, checkAnswer(translation, form, n))
+
+			if res == app.VerdictCorrect {
+				return res
+			}
+		}
+	}
+
+	return res
+}
+
+// Parenthesised segments are optional: "(to) run" can be typed
+// as "to run" and as "run". Returns the translation itself
+// and its' form without parenthesised segments.
+func withoutOptionalParts(translation string) []string {
+	var (
+		withoutParentheses strings.Builder
+		depth              int
+	)
+
+	for _, r := range translation {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			withoutParentheses.WriteRune(r)
+		}
+	}
+
+	if depth != 0 || withoutParentheses.Len() == len(translation) {
+		return []string{translation}
+	}
+
+	return []string{translation, withoutParentheses.String()}
+}
+
+// Compares normalized typed translation with the normalized right one.
+// Translations which differ by a few typos (the count of them depends on
+// the length of right translation) are almost correct.
+func checkAnswer(translation, rightTranslation string, n *normalizer) app.Verdict {
+	translation = n.Normalize(translation)
+	rightTranslation = n.Normalize(rightTranslation)
+
+	if translation == rightTranslation {
+		return app.VerdictCorrect
+	}
+
+	rightRunes := []rune(rightTranslation)
+
+	if editDistance([]rune(translation), rightRunes) <= allowedTypos(len(rightRunes)) {
+		return app.VerdictAlmostCorrect
+	}
+
+	return app.VerdictWrong
+}
+
+// Returns the max count of typos in almost correct translation.
+func allowedTypos(rightTranslationLength int) int {
+	return min(rightTranslationLength/ALMOST_CORRECT_RUNES_PER_TYPO, ALMOST_CORRECT_MAX_TYPOS)
+}
+
+// Returns the min count of insertions, deletions, substitutions of runes
+// and transpositions of adjacent runes needed to change a to b
+// (optimal string alignment distance).
+func editDistance(a, b []rune) int {
+	//Only three rows of the matrix are needed.
+	beforePrev := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			substitutionCost := 1
+
+			if a[i-1] == b[j-1] {
+				substitutionCost = 0
+			}
+
+			current[j] = min(
+				prev[j]+1,
+				current[j-1]+1,
+				prev[j-1]+substitutionCost,
+			)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], beforePrev[j-2]+1)
+			}
+		}
+
+		beforePrev, prev, current = prev, current, beforePrev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,167 @@
+package advanced
+
+import (
+	"testing"
+	"vocabulary/internal/app"
+)
+
+func testCheckAnswer(t *testing.T, translation, rightTranslation string, expected app.Verdict) {
+	testCheckNormalizedAnswer(t, newNormalizer("", &Normalization{}), translation, rightTranslation, expected)
+}
+
+func testCheckNormalizedAnswer(t *testing.T, n *normalizer, translation, rightTranslation string, expected app.Verdict) {
+	verdict := checkAnswer(translation, rightTranslation, n)
+
+	if verdict != expected {
+		t.Fatalf("checkAnswer(%q, %q) = %d, expected %d", translation, rightTranslation, verdict, expected)
+	}
+}
+
+func TestExactAnswer(t *testing.T) {
+	testCheckAnswer(t, "car", "car", app.VerdictCorrect)
+	testCheckAnswer(t, " Car ", "car", app.VerdictCorrect)
+	testCheckAnswer(t, "don't", "don’t", app.VerdictCorrect)
+}
+
+func TestNormalization(t *testing.T) {
+	//Composed and decomposed "é".
+	testCheckAnswer(t, "e\u0301cole", "\u00e9cole", app.VerdictCorrect)
+
+	testCheckAnswer(t, "Hello,   world!", "hello, world", app.VerdictCorrect)
+	testCheckAnswer(t, "well-known", "well known", app.VerdictAlmostCorrect)
+
+	testCheckNormalizedAnswer(t, newNormalizer("fr", &Normalization{}), "ecole", "école", app.VerdictWrong)
+	testCheckNormalizedAnswer(t, newNormalizer("fr", &Normalization{IgnoreDiacritics: true}), "ecole", "école", app.VerdictCorrect)
+
+	testCheckNormalizedAnswer(t, newNormalizer("ru", &Normalization{}), "елка", "ёлка", app.VerdictCorrect)
+	testCheckNormalizedAnswer(t, newNormalizer("de", &Normalization{}), "strasse", "Straße", app.VerdictCorrect)
+}
+
+func testCheckAnswerVariants(t *testing.T, n *normalizer, translation, rightTranslation string, expected app.Verdict) {
+	verdict := checkAnswerVariants(translation, []string{rightTranslation}, n)
+
+	if verdict != expected {
+		t.Fatalf("checkAnswerVariants(%q, %q) = %d, expected %d", translation, rightTranslation, verdict, expected)
+	}
+}
+
+func TestOptionalParts(t *testing.T) {
+	var (
+		withoutLanguage = newNormalizer("", &Normalization{})
+		english         = newNormalizer("en", &Normalization{})
+		german          = newNormalizer("de", &Normalization{})
+	)
+
+	testCheckAnswerVariants(t, withoutLanguage, "run", "(to) run", app.VerdictCorrect)
+	testCheckAnswerVariants(t, withoutLanguage, "to run", "(to) run", app.VerdictCorrect)
+	testCheckAnswerVariants(t, withoutLanguage, "Hund", "der Hund (m.)", app.VerdictWrong)
+	testCheckAnswerVariants(t, german, "Hund", "der Hund (m.)", app.VerdictCorrect)
+	testCheckAnswerVariants(t, german, "der Hund", "Hund", app.VerdictCorrect)
+
+	testCheckAnswerVariants(t, english, "run", "to run", app.VerdictCorrect)
+	testCheckAnswerVariants(t, english, "the", "the", app.VerdictCorrect)
+
+	custom := newNormalizer("en", &Normalization{IgnorableLeadingWords: map[string][]string{"en": {"to"}}})
+
+	testCheckAnswerVariants(t, custom, "run", "to run", app.VerdictCorrect)
+	testCheckAnswerVariants(t, custom, "apple", "an apple", app.VerdictWrong)
+}
+
+func TestAnswerWithTypos(t *testing.T) {
+	//Short answers should be typed without typos.
+	testCheckAnswer(t, "cat", "car", app.VerdictWrong)
+
+	testCheckAnswer(t, "recieve", "receive", app.VerdictAlmostCorrect)
+	testCheckAnswer(t, "recve", "receive", app.VerdictWrong)
+	testCheckAnswer(t, "to take smth into acount", "to take smth into account", app.VerdictAlmostCorrect)
+	testCheckAnswer(t, "to put smth into account", "to take smth into account", app.VerdictWrong)
+}
+
+func TestEditDistance(t *testing.T) {
+	distances := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"ёлка", "елка", 1},
+		{"flaw", "lawn", 2},
+		{"recieve", "receive", 1},
+		{"ca", "abc", 3},
+	}
+
+	for _, d := range distances {
+		distance := editDistance([]rune(d.a), []rune(d.b))
+
+		if distance != d.distance {
+			t.Fatalf("distance between %q and %q is %d, expected %d", d.a, d.b, distance, d.distance)
+		}
+	}
+}
+
+func TestAnswerVariants(t *testing.T) {
+	phrase := app.PhraseWithTranslation{
+		Phrase:              "big",
+		Translation:         "large / great",
+		TranslationVariants: app.SplitVariants("large / great", ";/"),
+	}
+
+	accepted := phrase.AcceptedTranslations()
+
+	for translation, expected := range map[string]app.Verdict{
+		"large":         app.VerdictCorrect,
+		"great":         app.VerdictCorrect,
+		"large / great": app.VerdictCorrect,
+		"larg":          app.VerdictWrong,
+		"small":         app.VerdictWrong,
+	} {
+		verdict := checkAnswerVariants(translation, accepted, newNormalizer("", &Normalization{}))
+
+		if verdict != expected {
+			t.Fatalf("checkAnswerVariants(%q, %q) = %d, expected %d", translation, accepted, verdict, expected)
+		}
+	}
+}
+
+func TestAlmostCorrectAnswerIsNotReveal(t *testing.T) {
+	reviews := []Review{}
+
+	lesson, err := New(
+		[]app.PhraseWithTranslation{{Phrase: "receive", Translation: "получать"}},
+		true,
+		WithReviewsLog(func(review Review) {
+			reviews = append(reviews, review)
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := lesson.Next(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manualTask := task.(app.TranslateManually)
+
+	if manualTask.RightTranslation() != "" {
+		t.Fatal("the right translation shouldn't be available before the answer")
+	}
+
+	verdict, err := manualTask.Right(t.Context(), "recieve")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verdict != app.VerdictAlmostCorrect || manualTask.RightTranslation() != "receive" {
+		t.Fatal("unexpected verdict or right translation:", verdict, manualTask.RightTranslation())
+	}
+
+	if len(reviews) != 1 || !reviews[0].AlmostCorrect || reviews[0].RightAnswerRequested {
+		t.Fatalf("one almost correct review is expected, got %+v", reviews)
+	}
+}
@@ -2,6 +2,7 @@ package advanced
 
 import (
 	"context"
+	"slices"
 	"vocabulary/internal/app"
 )
 
@@ -11,12 +12,56 @@ type oneOptionChoiceTask struct {
 	RightAnswer       int
 	IsInverted        bool
 	PhraseIndex       int
-	Solved            func(app.PhraseLearningTask, bool)
+	Solved            func(app.PhraseLearningTask, app.Verdict)
+	Reviewed          func(lessonTask, answerAttempt)
+
+	//Indexes of phrases of options.
+	OptionsPhrases []int
+
+	//Normalized texts of options which are right answers (the right
+	//translation and its' variants). Options equal to them are right too.
+	AcceptedOptions []string
+	Normalizer      *normalizer
 
 	alreadyAnswered bool
+	firstAnswer     int
+}
+
+var (
+	_ app.ChooseRightOption = (*oneOptionChoiceTask)(nil)
+	_ lessonTask            = (*oneOptionChoiceTask)(nil)
+)
+
+func (t *oneOptionChoiceTask) phraseIndex() int {
+	return t.PhraseIndex
+}
+
+func (t *oneOptionChoiceTask) kindOfTask() KindOfTask {
+	return KindOfTaskChooseOneOption
 }
 
-var _ app.ChooseRightOption = (*oneOptionChoiceTask)(nil)
+// Returns index of the phrase whose option was chosen by the first answer
+// instead of the right one or -1.
+func (t *oneOptionChoiceTask) confusedPhrase() int {
+	if !t.alreadyAnswered || t.firstAnswer < 0 || t.firstAnswer >= len(t.OptionsPhrases) || t.isRight(t.firstAnswer) {
+		return -1
+	}
+
+	return t.OptionsPhrases[t.firstAnswer]
+}
+
+// The right option and options with the same text (duplicates of the right translation) are right.
+func (t *oneOptionChoiceTask) isRight(option int) bool {
+	if option == t.RightAnswer {
+		return true
+	}
+
+	if option < 0 || option >= len(t.AvailableOptions) {
+		return false
+	}
+
+	return slices.Contains(t.AcceptedOptions, t.Normalizer.Normalize(t.AvailableOptions[option]))
+}
 
 func (t *oneOptionChoiceTask) Phrase() string {
 	return t.PhraseToTranslate
@@ -31,23 +76,149 @@ func (t *oneOptionChoiceTask) Options() []string {
 }
 
 func (t *oneOptionChoiceTask) Right(_ context.Context, option int) (bool, error) {
-	answerIsCorrect := option == t.RightAnswer
+	answerIsCorrect := t.isRight(option)
+
+	var answer string
+
+	if option >= 0 && option < len(t.AvailableOptions) {
+		answer = t.AvailableOptions[option]
+	}
+
+	t.Reviewed(t, answerAttempt{Correct: answerIsCorrect, Answer: answer})
 
 	if !t.alreadyAnswered {
 		t.alreadyAnswered = true
+		t.firstAnswer = option
 
-		t.Solved(t, answerIsCorrect)
+		verdict := app.VerdictWrong
+
+		if answerIsCorrect {
+			verdict = app.VerdictCorrect
+		}
+
+		t.Solved(t, verdict)
 	}
 
 	return answerIsCorrect, nil
 }
 
 func (t *oneOptionChoiceTask) GetRightAnswer(context.Context) (int, error) {
+	t.Reviewed(t, answerAttempt{RightAnswerRequested: true})
+
 	if !t.alreadyAnswered {
 		t.alreadyAnswered = true
+		t.firstAnswer = t.RightAnswer
 
-		t.Solved(t, false)
+		t.Solved(t, app.VerdictWrong)
 	}
 
 	return t.RightAnswer, nil
 }
+
+// Creates a task of choice of the right translation for phrase phraseIndex among optionsCount options.
+// Other options are translations of phrases chosen by distractorSelector. Options with the same
+// normalized text are shown once, so the task can have less options if the lesson has many duplicates.
+func newOneOptionChoiceTask(
+	distractorSelector DistractorSelector,
+	source *DistractorsSource,
+	n *normalizer,
+	phraseIndex int,
+	inverted bool,
+	optionsCount int,
+	solved func(app.PhraseLearningTask, app.Verdict),
+	reviewed func(lessonTask, answerAttempt),
+) (*oneOptionChoiceTask, error) {
+	//Extra candidates replace duplicates.
+	candidates, err := distractorSelector.SelectDistractors(
+		source,
+		phraseIndex,
+		inverted,
+		min((optionsCount-1)*2, source.PhrasesCount-1),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rightPhrase := source.PhraseAt(phraseIndex)
+
+	if inverted {
+		rightPhrase.Invert()
+	}
+
+	var (
+		accepted       = make([]string, 0, len(rightPhrase.TranslationVariants)+1)
+		phrasesIndexes = make([]int, 0, optionsCount)
+		shownOptions   = map[string]struct{}{}
+	)
+
+	for _, translation := range rightPhrase.AcceptedTranslations() {
+		normalized := n.Normalize(translation)
+
+		accepted = append(accepted, normalized)
+
+		shownOptions[normalized] = struct{}{}
+	}
+
+	for _, index := range candidates {
+		if len(phrasesIndexes) >= optionsCount-1 {
+			break
+		}
+
+		candidate := source.PhraseAt(index)
+
+		if inverted {
+			candidate.Invert()
+		}
+
+		normalized := n.Normalize(candidate.Translation)
+
+		if _, shown := shownOptions[normalized]; shown {
+			continue
+		}
+
+		shownOptions[normalized] = struct{}{}
+
+		phrasesIndexes = append(phrasesIndexes, index)
+	}
+
+	if len(phrasesIndexes) <= 0 {
+		return nil, app.ErrNotEnoughPhrasesInLesson
+	}
+
+	source.RandSource.Shuffle(
+		len(phrasesIndexes),
+		func(i, j int) {
+			phrasesIndexes[i], phrasesIndexes[j] = phrasesIndexes[j], phrasesIndexes[i]
+		},
+	)
+
+	right := source.RandSource.Intn(len(phrasesIndexes) + 1)
+
+	phrasesIndexes = slices.Insert(phrasesIndexes, right, phraseIndex)
+
+	options := make([]string, 0, len(phrasesIndexes))
+
+	for _, index := range phrasesIndexes {
+		toAdd := source.PhraseAt(index)
+
+		if inverted {
+			toAdd.Invert()
+		}
+
+		options = append(options, toAdd.Translation)
+	}
+
+	return &oneOptionChoiceTask{
+		PhraseToTranslate: rightPhrase.Phrase,
+		AvailableOptions:  options,
+		IsInverted:        inverted,
+		RightAnswer:       right,
+		PhraseIndex:       phraseIndex,
+		OptionsPhrases:    phrasesIndexes,
+		AcceptedOptions:   accepted,
+		Normalizer:        n,
+		Solved:            solved,
+		Reviewed:          reviewed,
+	}, nil
+}
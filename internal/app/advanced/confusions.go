@@ -0,0 +1,95 @@
+package advanced
+
+import (
+	"cmp"
+	"slices"
+	"time"
+	"vocabulary/internal/app"
+)
+
+// Called on each confusion of phrases in choice tasks (Count is 1).
+type ConfusionsLog func(app.Confusion)
+
+// Counts of confusions between phrases of a lesson: how many times the user
+// chose the option of one phrase instead of the other one (in both directions).
+type confusions map[int]map[int]uint32
+
+func (c confusions) add(a, b int, count uint32) {
+	for _, pair := range [...][2]int{{a, b}, {b, a}} {
+		if c[pair[0]] == nil {
+			c[pair[0]] = map[int]uint32{}
+		}
+
+		c[pair[0]][pair[1]] += count
+	}
+}
+
+// Adds confusions stored earlier. Confusions of phrases which
+// aren't in the lesson anymore are skipped.
+func (c confusions) load(stored []app.Confusion, phrasesCount int, phraseAt func(int) app.PhraseWithTranslation) {
+	if len(stored) <= 0 {
+		return
+	}
+
+	indexByPhrase := make(map[string]int, phrasesCount)
+
+	for i := range phrasesCount {
+		phrase := phraseAt(i).Phrase
+
+		if _, found := indexByPhrase[phrase]; !found {
+			indexByPhrase[phrase] = i
+		}
+	}
+
+	for _, confusion := range stored {
+		a, foundA := indexByPhrase[confusion.Phrase]
+		b, foundB := indexByPhrase[confusion.ConfusedWith]
+
+		if foundA && foundB && a != b {
+			c.add(a, b, confusion.Count)
+		}
+	}
+}
+
+// Counts the confusion made by the first answer to the choice task (if any) and logs it.
+func (c confusions) register(task *oneOptionChoiceTask, phraseAt func(int) app.PhraseWithTranslation, log ConfusionsLog) {
+	confused := task.confusedPhrase()
+
+	if confused == -1 {
+		return
+	}
+
+	c.add(task.PhraseIndex, confused, 1)
+
+	if log != nil {
+		log(
+			app.Confusion{
+				Phrase:       phraseAt(task.PhraseIndex).Phrase,
+				ConfusedWith: phraseAt(confused).Phrase,
+				Count:        1,
+				LastTimeUTC:  time.Now().UTC(),
+			},
+		)
+	}
+}
+
+// Returns indexes of phrases confused with the phrase, the most frequently confused first.
+func (c confusions) of(phraseIndex int) []int {
+	res := make([]int, 0, len(c[phraseIndex]))
+
+	for confused := range c[phraseIndex] {
+		res = append(res, confused)
+	}
+
+	slices.SortFunc(
+		res,
+		func(a, b int) int {
+			return cmp.Or(
+				cmp.Compare(c[phraseIndex][b], c[phraseIndex][a]),
+				cmp.Compare(a, b),
+			)
+		},
+	)
+
+	return res
+}
@@ -1,5 +1,7 @@
 package advanced
 
+import "time"
+
 var replacement = map[rune]rune{
 	'’': '\'',
 	'“': '"',
@@ -7,3 +9,68 @@ var replacement = map[rune]rune{
 	'«': '"',
 	'»': '"',
 }
+
+// Pairs of strings (the first one is replaced by the second one) which
+// are considered equal in answers in concrete languages.
+var languageEquivalences = map[string][]string{
+	"ru": {"ё", "е"},
+	"be": {"ё", "е"},
+	"de": {"ß", "ss", "ẞ", "ss"},
+}
+
+// Words which are ignored at the beginning of answers in concrete languages
+// (if Normalization.IgnorableLeadingWords doesn't define other ones).
+var defaultIgnorableLeadingWords = map[string][]string{
+	"en": {"to", "a", "an", "the"},
+	"de": {"der", "die", "das", "ein", "eine", "zu"},
+	"fr": {"le", "la", "les", "un", "une"},
+	"es": {"el", "la", "los", "las", "un", "una"},
+	"it": {"il", "lo", "la", "i", "gli", "le", "un", "uno", "una"},
+}
+
+// Count of options in tasks of choice of the right translation
+// (by default and the allowed range of WithOptionsCount()).
+const (
+	OPTIONS_COUNT     = 8
+	MIN_OPTIONS_COUNT = 2
+	MAX_OPTIONS_COUNT = 12
+)
+
+// Minimal weight of a phrase as a wrong option of choice tasks
+// (phrases which are learned now are chosen more often).
+const MIN_DISTRACTOR_WEIGHT = 0.1
+
+// Parameters of check of almost correct translations.
+const (
+	//One typo is acceptable per this count of runes of the right translation.
+	ALMOST_CORRECT_RUNES_PER_TYPO = 6
+
+	ALMOST_CORRECT_MAX_TYPOS = 3
+)
+
+// Parameters of SpacedRepetitionLesson.
+const (
+	//Max count of phrases seen for the first time during one lesson.
+	SRS_NEW_PHRASES_PER_LESSON = 20
+
+	//Difficulty of a phrase before its' first review.
+	SRS_INITIAL_DIFFICULTY = 0.3
+
+	//Changes of difficulty after right and wrong answers.
+	SRS_DIFFICULTY_DECREASE_ON_SUCCESS = 0.05
+	SRS_DIFFICULTY_INCREASE_ON_FAILURE = 0.15
+
+	//Stability (in days) after the first right answer.
+	SRS_FIRST_STABILITY = 1
+
+	//Growth factor of stability for the easiest (difficulty 0)
+	//and the hardest (difficulty 1) phrases.
+	SRS_MAX_STABILITY_GROWTH = 3
+	SRS_MIN_STABILITY_GROWTH = 1.3
+
+	//Part of stability which remains after a wrong answer.
+	SRS_STABILITY_REMAINDER_ON_FAILURE = 0.2
+
+	//The delay before repetition of a phrase answered wrong.
+	SRS_RELEARNING_DELAY = time.Minute
+)
@@ -0,0 +1,188 @@
+package advanced
+
+import (
+	"cmp"
+	"errors"
+	mathrand "math/rand"
+	"slices"
+	"strings"
+	"vocabulary/internal/app"
+	"vocabulary/internal/random"
+)
+
+// Data of the lesson available for choice of wrong options (distractors) of choice tasks.
+type DistractorsSource struct {
+	PhrasesCount int
+	PhraseAt     func(int) app.PhraseWithTranslation
+	RandSource   *mathrand.Rand
+
+	//Samples indexes of phrases according to their weights
+	//(phrases which are learned now have bigger weights).
+	Sampler *random.DiscreteRandomVariable
+
+	//Returns indexes of phrases which were confused by the user
+	//with the phrase (the most frequently confused first).
+	Confusions func(phraseIndex int) []int
+}
+
+// Chooses wrong options of choice tasks.
+type DistractorSelector interface {
+	//Returns count distinct indexes of phrases (except phraseIndex) whose translations
+	//(phrases if the task is inverted) can be wrong options of the task, the most suitable first.
+	SelectDistractors(source *DistractorsSource, phraseIndex int, inverted bool, count int) ([]int, error)
+}
+
+// Chooses wrong options randomly (according to weights of phrases).
+type RandomDistractors struct{}
+
+var _ DistractorSelector = RandomDistractors{}
+
+func (RandomDistractors) SelectDistractors(source *DistractorsSource, phraseIndex int, _ bool, count int) ([]int, error) {
+	res, err := source.Sampler.GetDistinct(count, phraseIndex)
+
+	if errors.Is(err, random.ErrNotEnoughValues) {
+		return nil, app.ErrNotEnoughPhrasesInLesson
+	}
+
+	return res, err
+}
+
+// Chooses wrong options which are the most similar to the right one among
+// randomly sampled candidates and phrases confused by the user earlier.
+// Similarity is a weighted sum of measures from 0 to 1.
+type SimilarDistractors struct {
+	//Count of sampled candidates per distractor. The bigger it is,
+	//the more similar (and the less random) distractors are.
+	CandidatesPerDistractor int
+
+	//Similarity of lengths of options.
+	LengthWeight float64
+	//Length of the common prefix relative to the shortest option.
+	PrefixWeight float64
+	//Edit distance relative to the longest option.
+	EditDistanceWeight float64
+	//Options have the same non-empty tag (for example, part of speech).
+	TagWeight float64
+	//The user confused phrases earlier.
+	ConfusionWeight float64
+}
+
+var _ DistractorSelector = (*SimilarDistractors)(nil)
+
+// Returns the selector used by lessons by default.
+func DefaultSimilarDistractors() *SimilarDistractors {
+	return &SimilarDistractors{
+		CandidatesPerDistractor: 4,
+		LengthWeight:            1,
+		PrefixWeight:            1,
+		EditDistanceWeight:      1,
+		TagWeight:               2,
+		ConfusionWeight:         5,
+	}
+}
+
+type distractorCandidate struct {
+	PhraseIndex int
+	Similarity  float64
+}
+
+func (s *SimilarDistractors) SelectDistractors(source *DistractorsSource, phraseIndex int, inverted bool, count int) ([]int, error) {
+	if count > source.PhrasesCount-1 {
+		return nil, app.ErrNotEnoughPhrasesInLesson
+	}
+
+	var confused []int
+
+	if source.Confusions != nil {
+		confused = source.Confusions(phraseIndex)
+
+		confused = confused[:min(len(confused), count)]
+	}
+
+	sampled, err := source.Sampler.GetDistinct(
+		min(count*max(s.CandidatesPerDistractor, 1), source.PhrasesCount-1)-len(confused),
+		append([]int{phraseIndex}, confused...)...,
+	)
+
+	if errors.Is(err, random.ErrNotEnoughValues) {
+		return nil, app.ErrNotEnoughPhrasesInLesson
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		right      = source.PhraseAt(phraseIndex)
+		candidates = make([]distractorCandidate, 0, len(confused)+len(sampled))
+	)
+
+	if inverted {
+		right.Invert()
+	}
+
+	for i, index := range slices.Concat(confused, sampled) {
+		candidate := source.PhraseAt(index)
+
+		if inverted {
+			candidate.Invert()
+		}
+
+		similarity := s.similarity(&right, &candidate)
+
+		if i < len(confused) {
+			similarity += s.ConfusionWeight
+		}
+
+		candidates = append(candidates, distractorCandidate{PhraseIndex: index, Similarity: similarity})
+	}
+
+	slices.SortStableFunc(
+		candidates,
+		func(a, b distractorCandidate) int {
+			return cmp.Compare(b.Similarity, a.Similarity)
+		},
+	)
+
+	res := make([]int, count)
+
+	for i := range res {
+		res[i] = candidates[i].PhraseIndex
+	}
+
+	return res, nil
+}
+
+// Returns similarity of the option of candidate to the right option.
+// Options equal to the right one are the least suitable (they are right answers too).
+func (s *SimilarDistractors) similarity(right, candidate *app.PhraseWithTranslation) float64 {
+	var (
+		rightRunes     = []rune(strings.ToLower(right.Translation))
+		candidateRunes = []rune(strings.ToLower(candidate.Translation))
+		shortest       = min(len(rightRunes), len(candidateRunes))
+		longest        = max(len(rightRunes), len(candidateRunes))
+	)
+
+	if slices.Equal(rightRunes, candidateRunes) {
+		return -1
+	}
+
+	commonPrefix := 0
+
+	for commonPrefix < shortest && rightRunes[commonPrefix] == candidateRunes[commonPrefix] {
+		commonPrefix++
+	}
+
+	res := s.LengthWeight*float64(shortest)/float64(longest) +
+		s.EditDistanceWeight*(1-float64(editDistance(rightRunes, candidateRunes))/float64(longest))
+
+	if shortest > 0 {
+		res += s.PrefixWeight * float64(commonPrefix) / float64(shortest)
+	}
+
+	if right.Tag != "" && right.Tag == candidate.Tag {
+		res += s.TagWeight
+	}
+
+	return res
+}
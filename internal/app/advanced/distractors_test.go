@@ -0,0 +1,212 @@
+package advanced
+
+import (
+	mathrand "math/rand"
+	"slices"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/random"
+)
+
+func testDistractorsSource(t *testing.T, phrases []app.PhraseWithTranslation, confused map[int][]int) *DistractorsSource {
+	randSource := mathrand.New(mathrand.NewSource(0))
+
+	weights := make([]float64, len(phrases))
+
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	sampler, err := random.NewDiscreteRandomVariable(randSource, weights)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &DistractorsSource{
+		PhrasesCount: len(phrases),
+		PhraseAt: func(i int) app.PhraseWithTranslation {
+			return phrases[i]
+		},
+		RandSource: randSource,
+		Sampler:    sampler,
+		Confusions: func(phraseIndex int) []int {
+			return confused[phraseIndex]
+		},
+	}
+}
+
+func testSelectedDistractors(t *testing.T, source *DistractorsSource, count int, expected ...int) {
+	selector := DefaultSimilarDistractors()
+
+	//All the phrases are candidates.
+	selector.CandidatesPerDistractor = source.PhrasesCount
+
+	distractors, err := selector.SelectDistractors(source, 0, false, count)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(distractors)
+
+	if !slices.Equal(distractors, expected) {
+		t.Fatal("expected distractors", expected, "got", distractors)
+	}
+}
+
+func TestSimilarDistractors(t *testing.T) {
+	phrases := []app.PhraseWithTranslation{
+		{Phrase: "laufen", Translation: "to run", Tag: "verb"},
+		{Phrase: "das Haus", Translation: "the house", Tag: "noun"},
+		{Phrase: "rennen", Translation: "to race", Tag: "verb"},
+		{Phrase: "die Unabhängigkeit", Translation: "independence", Tag: "noun"},
+		{Phrase: "rufen", Translation: "to call", Tag: "verb"},
+		{Phrase: "der Tisch", Translation: "table", Tag: "noun"},
+		{Phrase: "sich beeilen", Translation: "to run", Tag: "verb"},
+	}
+
+	testSelectedDistractors(t, testDistractorsSource(t, phrases, nil), 2, 2, 4)
+
+	//Confused phrases are preferred.
+	testSelectedDistractors(t, testDistractorsSource(t, phrases, map[int][]int{0: {3}}), 2, 2, 3)
+
+	//Options equal to the right one are chosen only when there is no other choice.
+	testSelectedDistractors(t, testDistractorsSource(t, phrases, nil), 6, 1, 2, 3, 4, 5, 6)
+
+	_, err := RandomDistractors{}.SelectDistractors(testDistractorsSource(t, phrases, nil), 0, false, 7)
+
+	if err != app.ErrNotEnoughPhrasesInLesson {
+		t.Fatal("ErrNotEnoughPhrasesInLesson should be returned, got:", err)
+	}
+}
+
+func TestConfusions(t *testing.T) {
+	c := confusions{}
+
+	c.add(1, 2, 1)
+	c.add(1, 3, 1)
+	c.add(3, 1, 1)
+
+	if !slices.Equal(c.of(1), []int{3, 2}) {
+		t.Fatal("wrong confusions of phrase:", c.of(1))
+	}
+
+	if !slices.Equal(c.of(2), []int{1}) {
+		t.Fatal("confusions should be symmetric:", c.of(2))
+	}
+}
+
+func TestStoredConfusionsArePaired(t *testing.T) {
+	phrases := testPhrases(50)
+
+	stored := []app.Confusion{{Phrase: phrases[0].Phrase, ConfusedWith: phrases[42].Phrase, Count: 1}}
+
+	var logged []app.Confusion
+
+	lesson, err := New(
+		phrases,
+		false,
+		WithSeed(0),
+		WithConfusions(stored),
+		WithConfusionsLog(
+			func(confusion app.Confusion) {
+				logged = append(logged, confusion)
+			},
+		),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := newOneOptionChoiceTask(lesson.distractorSelector, &lesson.distractors, lesson.normalizers.forTask(false), 0, false, OPTIONS_COUNT, lesson.taskSolved, lesson.taskReviewed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confusedOption := slices.Index(task.OptionsPhrases, 42)
+
+	if confusedOption == -1 {
+		t.Fatal("confused phrase should be an option:", task.OptionsPhrases)
+	}
+
+	task.Right(t.Context(), confusedOption)
+
+	if len(logged) != 1 || logged[0].Phrase != phrases[0].Phrase || logged[0].ConfusedWith != phrases[42].Phrase {
+		t.Fatal("confusion wasn't logged:", logged)
+	}
+
+	if !slices.Equal(lesson.confusions.of(42), []int{0}) {
+		t.Fatal("confusion wasn't counted:", lesson.confusions.of(42))
+	}
+}
+
+func TestDuplicateOptions(t *testing.T) {
+	phrases := testPhrases(12)
+
+	for i := range 6 {
+		phrases[i].Translation = "The same"
+	}
+
+	phrases[6].Translation = "the same!"
+
+	for _, selector := range []DistractorSelector{RandomDistractors{}, DefaultSimilarDistractors()} {
+		lesson, err := New(phrases, false, WithSeed(0), WithDistractorSelector(selector))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for range 20 {
+			task, err := newOneOptionChoiceTask(lesson.distractorSelector, &lesson.distractors, lesson.normalizers.forTask(false), 0, false, OPTIONS_COUNT, lesson.taskSolved, lesson.taskReviewed)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(task.Options()) != 6 {
+				t.Fatal("duplicates should be replaced by other phrases:", task.Options())
+			}
+
+			shown := map[string]bool{}
+
+			for _, option := range task.Options() {
+				normalized := lesson.normalizers.forTask(false).Normalize(option)
+
+				if shown[normalized] {
+					t.Fatal("options shouldn't be repeated:", task.Options())
+				}
+
+				shown[normalized] = true
+			}
+		}
+	}
+}
+
+func TestOptionEqualToRightIsAccepted(t *testing.T) {
+	n := newNormalizer("", &Normalization{})
+
+	task := &oneOptionChoiceTask{
+		AvailableOptions: []string{"car", "Car!", "cat"},
+		OptionsPhrases:   []int{0, 1, 2},
+		RightAnswer:      0,
+		AcceptedOptions:  []string{n.Normalize("car")},
+		Normalizer:       n,
+		Solved:           func(app.PhraseLearningTask, app.Verdict) {},
+		Reviewed:         func(lessonTask, answerAttempt) {},
+	}
+
+	if right, _ := task.Right(t.Context(), 1); !right {
+		t.Fatal("option equal to the right one should be accepted")
+	}
+
+	if task.confusedPhrase() != -1 {
+		t.Fatal("right answer isn't a confusion")
+	}
+
+	if right, _ := task.Right(t.Context(), 2); right {
+		t.Fatal("other option shouldn't be accepted")
+	}
+}
@@ -0,0 +1,19 @@
+package advanced
+
+import "vocabulary/internal/app"
+
+// Identifies a phrase with its' translation in all the lessons: the same word
+// in different sheets and files has the same key (unlike app.PhraseKey).
+type KnowledgeKey struct {
+	Phrase, Translation string
+}
+
+// Only language independent rules are applied, so keys don't depend on settings of lessons.
+var knowledgeNormalizer = newNormalizer("", &Normalization{})
+
+func NewKnowledgeKey(phrase *app.PhraseWithTranslation) KnowledgeKey {
+	return KnowledgeKey{
+		Phrase:      knowledgeNormalizer.Normalize(phrase.Phrase),
+		Translation: knowledgeNormalizer.Normalize(phrase.Translation),
+	}
+}
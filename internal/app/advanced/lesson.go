@@ -1,21 +1,19 @@
 package advanced
 
 import (
-	"bytes"
 	"context"
-	cryptorand "crypto/rand"
-	"encoding/binary"
 	"errors"
+	"fmt"
 	mathrand "math/rand"
 	"vocabulary/internal/app"
 	"vocabulary/internal/random"
 )
 
-type kindOfTask int
+type KindOfTask int
 
 const (
-	kindOfTaskChooseOneOption kindOfTask = iota
-	kindOfTaskTranslateManually
+	KindOfTaskChooseOneOption KindOfTask = iota
+	KindOfTaskTranslateManually
 )
 
 type PhraseLearningStatistics struct {
@@ -27,6 +25,10 @@ type PhraseLearningStatistics struct {
 	CountFailedOOSInverted  uint32
 	CountAnsweredTMInverted uint32
 	CountFailedTMInverted   uint32
+
+	//Manual translations with a few typos.
+	CountAlmostTM         uint32
+	CountAlmostTMInverted uint32
 }
 
 func (s *PhraseLearningStatistics) IsEmpty() bool {
@@ -37,7 +39,26 @@ func (s *PhraseLearningStatistics) IsEmpty() bool {
 		s.CountGuessedOOSInverted == 0 &&
 		s.CountFailedOOSInverted == 0 &&
 		s.CountAnsweredTMInverted == 0 &&
-		s.CountFailedTMInverted == 0
+		s.CountFailedTMInverted == 0 &&
+		s.CountAlmostTM == 0 &&
+		s.CountAlmostTMInverted == 0
+}
+
+// Returns statistics whose each counter is the result of combine of the same counters
+// of both statistics (for example, their' sum).
+func (s *PhraseLearningStatistics) Combine(other *PhraseLearningStatistics, combine func(a, b uint32) uint32) PhraseLearningStatistics {
+	return PhraseLearningStatistics{
+		CountGuessedOOS:         combine(s.CountGuessedOOS, other.CountGuessedOOS),
+		CountFailedOOS:          combine(s.CountFailedOOS, other.CountFailedOOS),
+		CountAnsweredTM:         combine(s.CountAnsweredTM, other.CountAnsweredTM),
+		CountFailedTM:           combine(s.CountFailedTM, other.CountFailedTM),
+		CountGuessedOOSInverted: combine(s.CountGuessedOOSInverted, other.CountGuessedOOSInverted),
+		CountFailedOOSInverted:  combine(s.CountFailedOOSInverted, other.CountFailedOOSInverted),
+		CountAnsweredTMInverted: combine(s.CountAnsweredTMInverted, other.CountAnsweredTMInverted),
+		CountFailedTMInverted:   combine(s.CountFailedTMInverted, other.CountFailedTMInverted),
+		CountAlmostTM:           combine(s.CountAlmostTM, other.CountAlmostTM),
+		CountAlmostTMInverted:   combine(s.CountAlmostTMInverted, other.CountAlmostTMInverted),
+	}
 }
 
 type PhraseWithLearningStatistics struct {
@@ -45,6 +66,11 @@ type PhraseWithLearningStatistics struct {
 	LearningStatistics PhraseLearningStatistics
 }
 
+// Called with the updated statistics of a phrase after the first answer to a task.
+// It is called synchronously from app.ChooseRightOption.Right() and
+// app.TranslateManually.Right() (and GetRightAnswer()), so it shouldn't block for long.
+type ProgressListener func(PhraseWithLearningStatistics)
+
 type phraseWithStatisticsAndTasksIndexes struct {
 	Phrase             app.PhraseWithTranslation
 	LearningStatistics PhraseLearningStatistics
@@ -58,7 +84,7 @@ type phraseWithStatisticsAndTasksIndexes struct {
 type taskCreationData struct {
 	PhraseIndex int
 	Inverted    bool
-	KnidOfTask  kindOfTask
+	KnidOfTask  KindOfTask
 }
 
 type weightWithIndex struct {
@@ -76,7 +102,7 @@ type phraseWithTasksWeights struct {
 // Tasks order is random, but probability of choice of concrete
 // task depends on user answers.
 //
-// Mehod  calculateWeightOfTask() contains the algorithm of prioritizing tasks.
+// The algorithm of prioritizing tasks is provided by WeightingStrategy.
 type Lesson struct {
 	//Slace phrases is four times shorter than tasksProperties because each phrase is connected
 	//with 4 tasks: choose one option, choose one option inverted, translate manually and
@@ -85,12 +111,22 @@ type Lesson struct {
 	phrases         []phraseWithStatisticsAndTasksIndexes
 	tasksProperties []taskCreationData
 
-	//Each value recieved from tasksSelector.Get() method is an index in tasksProperties slice.
+	//Each value recieved from tasksSelector.TryGet() method is an index in tasksProperties slice.
 	//Weight of i-th value provides a probability of return i-th task by Next() method.
 	//(weight - unnormilized probability).
 	tasksSelector *random.DiscreteRandomVariable
 	randSource    *mathrand.Rand
 
+	//Each value recieved from distractors.Sampler is an index in phrases slice.
+	//Phrases which are learned now are more plausible wrong options in choice tasks.
+	distractors        DistractorsSource
+	distractorSelector DistractorSelector
+	confusions         confusions
+	confusionsLog      ConfusionsLog
+
+	//Count of options in choice tasks (adapted to the count of phrases).
+	optionsCount int
+
 	//Short history of used phrases. Filled from last to first element. Can contain nil at the beginning
 	//of lesson. Number 4 can be increased to decrease the probability of too often usage of one phrase.
 	//
@@ -102,11 +138,22 @@ type Lesson struct {
 	lastPhrasesToNotRepeat [4]*phraseWithTasksWeights
 
 	spellingOnly bool
+
+	weighting        WeightingStrategy
+	reviewsLog       ReviewsLog
+	progressListener ProgressListener
+	normalizers      normalizers
+
+	//The last error of the weighting strategy (invalid weight of task).
+	weightingErr error
+
+	seed      int64
+	seedKnown bool
 }
 
 var _ app.Lesson = (*Lesson)(nil)
 
-func New(phrases []app.PhraseWithTranslation, spellingOnly bool) (*Lesson, error) {
+func New(phrases []app.PhraseWithTranslation, spellingOnly bool, opts ...Option) (*Lesson, error) {
 	withStatistics := make([]PhraseWithLearningStatistics, len(phrases))
 
 	for i, phrase := range phrases {
@@ -116,25 +163,29 @@ func New(phrases []app.PhraseWithTranslation, spellingOnly bool) (*Lesson, error
 		withStatsLine.LearningStatistics = PhraseLearningStatistics{}
 	}
 
-	return newWithProgress(withStatistics, spellingOnly)
+	return newWithProgress(withStatistics, spellingOnly, opts)
 }
 
-func NewWithProgress(phrases []PhraseWithLearningStatistics) (*Lesson, error) {
-	return newWithProgress(phrases, false)
+// Unlike New, continues learning with the statistics of phrases. Spelling lessons
+// use and update the same statistics of inverted manual translation tasks.
+func NewWithProgress(phrases []PhraseWithLearningStatistics, spellingOnly bool, opts ...Option) (*Lesson, error) {
+	return newWithProgress(phrases, spellingOnly, opts)
 }
 
 func (l *Lesson) SpellingOnly() bool {
 	return l.spellingOnly
 }
 
-func newWithProgress(phrases []PhraseWithLearningStatistics, spellingOnly bool) (*Lesson, error) {
+func newWithProgress(phrases []PhraseWithLearningStatistics, spellingOnly bool, opts []Option) (*Lesson, error) {
 	var (
+		settings              = newSettings(opts)
 		phrasesWithStatistics = make([]phraseWithStatisticsAndTasksIndexes, len(phrases))
 		tasksProperties       = make([]taskCreationData, 0, len(phrases)*4)
 		weights               = make([]float64, 0, len(phrases)*4)
+		distractorsWeights    = make([]float64, len(phrases))
 	)
 
-	addTask := func(i int, stats *PhraseLearningStatistics, kindOfTask kindOfTask, inverted bool) int {
+	addTask := func(i int, stats *PhraseLearningStatistics, kindOfTask KindOfTask, inverted bool) int {
 		tcd := taskCreationData{
 			PhraseIndex: i,
 			Inverted:    inverted,
@@ -150,7 +201,7 @@ func newWithProgress(phrases []PhraseWithLearningStatistics, spellingOnly bool)
 
 		weights = append(
 			weights,
-			calculateWeightOfTask(
+			settings.weighting.WeightOfTask(
 				stats,
 				tcd.KnidOfTask,
 				tcd.Inverted,
@@ -167,31 +218,31 @@ func newWithProgress(phrases []PhraseWithLearningStatistics, spellingOnly bool)
 			LearningStatistics: phrase.LearningStatistics,
 		}
 
-		pwsati.IndexOfChooseRightOptionTask = addTask(i, &pwsati.LearningStatistics, kindOfTaskChooseOneOption, false)
-
-		pwsati.IndexOfChooseRightOptionInvertedTask = addTask(i, &pwsati.LearningStatistics, kindOfTaskChooseOneOption, true)
+		if pwsati.LearningStatistics.IsEmpty() {
+			if known, found := settings.knowledge[NewKnowledgeKey(&pwsati.Phrase)]; found {
+				pwsati.LearningStatistics = known
+			}
+		}
 
-		pwsati.IndexOfTranslateManuallyTask = addTask(i, &pwsati.LearningStatistics, kindOfTaskTranslateManually, false)
+		pwsati.IndexOfChooseRightOptionTask = addTask(i, &pwsati.LearningStatistics, KindOfTaskChooseOneOption, false)
 
-		pwsati.IndexOfTranslateManuallyInvertedTask = addTask(i, &pwsati.LearningStatistics, kindOfTaskTranslateManually, true)
+		pwsati.IndexOfChooseRightOptionInvertedTask = addTask(i, &pwsati.LearningStatistics, KindOfTaskChooseOneOption, true)
 
-		phrasesWithStatistics[i] = pwsati
-	}
+		pwsati.IndexOfTranslateManuallyTask = addTask(i, &pwsati.LearningStatistics, KindOfTaskTranslateManually, false)
 
-	randomKey := make([]byte, 8)
+		pwsati.IndexOfTranslateManuallyInvertedTask = addTask(i, &pwsati.LearningStatistics, KindOfTaskTranslateManually, true)
 
-	cryptorand.Read(randomKey)
+		phrasesWithStatistics[i] = pwsati
 
-	var int64Source int64
+		distractorsWeights[i] = distractorWeight(weights[len(weights)-4:]...)
+	}
 
-	err := binary.Read(bytes.NewReader(randomKey), binary.BigEndian, &int64Source)
+	randSource, err := settings.newRandSource()
 
 	if err != nil {
 		return nil, err
 	}
 
-	randSource := mathrand.New(mathrand.NewSource(int64Source))
-
 	tasksSelector, err := random.NewDiscreteRandomVariable(randSource, weights)
 
 	if errors.Is(err, random.ErrEmptyWeightsSlice) {
@@ -199,183 +250,117 @@ func newWithProgress(phrases []PhraseWithLearningStatistics, spellingOnly bool)
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("weighting strategy: %w", err)
 	}
 
-	return &Lesson{
-		phrases:         phrasesWithStatistics,
-		randSource:      randSource,
-		tasksProperties: tasksProperties,
-		tasksSelector:   tasksSelector,
-		spellingOnly:    spellingOnly,
-	}, nil
-}
-
-// Contains the logick of prioritizing tasks for their right order in lesson
-// and more productive learning.
-func calculateWeightOfTask(learningStatistics *PhraseLearningStatistics, kindOfTask kindOfTask, taskInverted bool, spellingOnly bool) float64 {
-	if spellingOnly {
-		if kindOfTask == kindOfTaskTranslateManually && taskInverted {
-			return 1
-		}
+	distractorsSampler, err := random.NewDiscreteRandomVariable(randSource, distractorsWeights)
 
-		return 0
+	if err != nil {
+		return nil, err
 	}
 
-	OneOptionChoiceTasksPassed := learningStatistics.CountGuessedOOS +
-		learningStatistics.CountFailedOOS +
-		learningStatistics.CountGuessedOOSInverted +
-		learningStatistics.CountFailedOOSInverted
-
-	OneOptionChoiceTasksPassedSuccessfully := learningStatistics.CountGuessedOOS +
-		learningStatistics.CountGuessedOOSInverted
-
-	//First 3 tasks are always translation from foreigh language
-	//to known one by selecting the right card.
-	if OneOptionChoiceTasksPassedSuccessfully < 3 {
-		if kindOfTask == kindOfTaskChooseOneOption && !taskInverted {
-			return 1
-		}
-
-		return 0
+	res := &Lesson{
+		phrases:            phrasesWithStatistics,
+		randSource:         randSource,
+		tasksProperties:    tasksProperties,
+		tasksSelector:      tasksSelector,
+		distractorSelector: settings.distractorSelector,
+		confusions:         confusions{},
+		confusionsLog:      settings.confusionsLog,
+		optionsCount:       settings.optionsCountFor(len(phrases)),
+		spellingOnly:       spellingOnly,
+		weighting:          settings.weighting,
+		reviewsLog:         settings.reviewsLog,
+		progressListener:   settings.progressListener,
+		normalizers:        newNormalizers(settings.normalization),
+		seed:               settings.seed,
+		seedKnown:          settings.seedKnown,
 	}
 
-	//Than mixed mode of cards: to foregn and to known language.
-	if OneOptionChoiceTasksPassedSuccessfully < 7 {
-		if kindOfTask == kindOfTaskChooseOneOption {
-			//2 tasks are available for 1 phrase.
-			//Weight 1.0 will cause that this phrase will become more prioritized
-			//than other (sum of all tasks' weights for one phrase should be always 1.0).
-			return 0.5
-		}
-
-		return 0
+	res.distractors = DistractorsSource{
+		PhrasesCount: len(phrasesWithStatistics),
+		PhraseAt:     res.phraseAt,
+		RandSource:   randSource,
+		Sampler:      distractorsSampler,
+		Confusions:   res.confusions.of,
 	}
 
-	//7-10-th tasks by concrete phrase always will be translation
-	//from known language to foreign by cards.
-	if OneOptionChoiceTasksPassedSuccessfully < 10 {
-		if kindOfTask == kindOfTaskChooseOneOption && taskInverted {
-			return 1
-		}
-
-		return 0
-	}
+	res.confusions.load(settings.confusions, res.distractors.PhrasesCount, res.phraseAt)
 
-	if float64(OneOptionChoiceTasksPassedSuccessfully)/float64(OneOptionChoiceTasksPassed) < 0.7 {
-		if kindOfTask == kindOfTaskChooseOneOption || (kindOfTask == kindOfTaskTranslateManually && !taskInverted) {
-			//3 tasks are available for 1 phrase.
-			//Weight 1.0 will cause that this phrase will become more prioritized
-			//than other (sum of all tasks' weights for one phrase should be always 1.0).
-			return float64(1) / float64(3)
-		}
+	return res, nil
+}
 
-		return 0
-	}
+// Returns the weight of phrase as a wrong option of choice tasks by weights of its' tasks.
+// Never returns zero, so any phrase can be a wrong option.
+func distractorWeight(tasksWeights ...float64) float64 {
+	res := float64(0)
 
-	TranslateManuallyTasksPassed := learningStatistics.CountAnsweredTM +
-		learningStatistics.CountFailedTM +
-		learningStatistics.CountAnsweredTMInverted +
-		learningStatistics.CountFailedTMInverted
-
-	TranslateManuallyTasksPassedSuccessfully := learningStatistics.CountAnsweredTM +
-		learningStatistics.CountAnsweredTMInverted
-
-	//When the phrase is complitely learned, we need to
-	//make it less prioritized (to improve learning of other).
-	//Don't set this weight to zero! It will cause
-	//repetition of one phrase again and again when all the phrases
-	//are learned.
-	if TranslateManuallyTasksPassed > 10 && float64(TranslateManuallyTasksPassedSuccessfully)/float64(TranslateManuallyTasksPassed) > 0.9 {
-		return 0.1
+	for _, weight := range tasksWeights {
+		res += weight
 	}
 
-	//All further learning (before 10 attempts with more than 90% probability of right answer)
-	//will contain only manual translation.
-	if kindOfTask == kindOfTaskTranslateManually {
-		return 0.5
-	}
+	return max(res, MIN_DISTRACTOR_WEIGHT)
+}
 
-	return 0
+// Returns the seed of random numbers generator of the lesson.
+// The lesson created with the same seed and phrases will return the same tasks
+// if the same answers are given. The seed is unknown if the lesson was created
+// with WithRandSource() option.
+func (l *Lesson) Seed() (seed int64, known bool) {
+	return l.seed, l.seedKnown
 }
 
 // Changes weights of all the tasks connected with phrase.
-func (l *Lesson) setWeightsToTasks(pwsati *phraseWithStatisticsAndTasksIndexes, _ kindOfTask, _ bool) {
-	l.tasksSelector.SetWeight(
-		pwsati.IndexOfChooseRightOptionTask,
-		calculateWeightOfTask(
-			&pwsati.LearningStatistics,
-			kindOfTaskChooseOneOption,
-			false,
-			l.spellingOnly,
-		),
-	)
+func (l *Lesson) setWeightsToTasks(pwsati *phraseWithStatisticsAndTasksIndexes, _ KindOfTask, _ bool) {
+	l.setWeightToTask(pwsati, pwsati.IndexOfChooseRightOptionTask, KindOfTaskChooseOneOption, false)
 
-	l.tasksSelector.SetWeight(
-		pwsati.IndexOfChooseRightOptionInvertedTask,
-		calculateWeightOfTask(
-			&pwsati.LearningStatistics,
-			kindOfTaskChooseOneOption,
-			true,
-			l.spellingOnly,
-		),
-	)
+	l.setWeightToTask(pwsati, pwsati.IndexOfChooseRightOptionInvertedTask, KindOfTaskChooseOneOption, true)
 
-	l.tasksSelector.SetWeight(
-		pwsati.IndexOfTranslateManuallyTask,
-		calculateWeightOfTask(
-			&pwsati.LearningStatistics,
-			kindOfTaskTranslateManually,
-			false,
-			l.spellingOnly,
+	l.setWeightToTask(pwsati, pwsati.IndexOfTranslateManuallyTask, KindOfTaskTranslateManually, false)
+
+	l.setWeightToTask(pwsati, pwsati.IndexOfTranslateManuallyInvertedTask, KindOfTaskTranslateManually, true)
+
+	l.distractors.Sampler.SetWeight(
+		l.tasksProperties[pwsati.IndexOfChooseRightOptionTask].PhraseIndex,
+		distractorWeight(
+			l.tasksSelector.GetWeight(pwsati.IndexOfChooseRightOptionTask),
+			l.tasksSelector.GetWeight(pwsati.IndexOfChooseRightOptionInvertedTask),
+			l.tasksSelector.GetWeight(pwsati.IndexOfTranslateManuallyTask),
+			l.tasksSelector.GetWeight(pwsati.IndexOfTranslateManuallyInvertedTask),
 		),
 	)
+}
 
-	l.tasksSelector.SetWeight(
-		pwsati.IndexOfTranslateManuallyInvertedTask,
-		calculateWeightOfTask(
+// Changes weight of the task by the weighting strategy. Invalid weight isn't set,
+// the error is returned by the next call of Next().
+func (l *Lesson) setWeightToTask(pwsati *phraseWithStatisticsAndTasksIndexes, taskIndex int, kindOfTask KindOfTask, inverted bool) {
+	err := l.tasksSelector.SetWeight(
+		taskIndex,
+		l.weighting.WeightOfTask(
 			&pwsati.LearningStatistics,
-			kindOfTaskTranslateManually,
-			true,
+			kindOfTask,
+			inverted,
 			l.spellingOnly,
 		),
 	)
-}
 
-// Returns a slice of random unique indexes for slice l.phrases.
-// Ignores tasks weights and statistics.
-func (l *Lesson) getRandomPhrasesIndexes(n int) ([]int, error) {
-	if n > len(l.phrases) {
-		return nil, app.ErrNotEnoughPhrasesInLesson
-	}
-
-	indexes := make([]int, len(l.phrases))
-
-	for i := range l.phrases {
-		indexes[i] = i
+	if err != nil {
+		l.weightingErr = fmt.Errorf("weighting strategy: %w", err)
 	}
-
-	l.randSource.Shuffle(
-		len(l.phrases),
-		func(i, j int) {
-			indexes[i], indexes[j] = indexes[j], indexes[i]
-		},
-	)
-
-	return indexes[:n], nil
 }
 
 // Gathers all the statistics and changes tasks' weights.
-func (l *Lesson) taskSolved(task app.PhraseLearningTask, success bool) {
+func (l *Lesson) taskSolved(task app.PhraseLearningTask, verdict app.Verdict) {
 	var (
+		success     = verdict == app.VerdictCorrect
 		phraseIndex int
 		pwsati      *phraseWithStatisticsAndTasksIndexes
-		kindOfTask  kindOfTask
+		kindOfTask  KindOfTask
 	)
 
 	switch t := task.(type) {
 	case *oneOptionChoiceTask:
-		kindOfTask = kindOfTaskChooseOneOption
+		kindOfTask = KindOfTaskChooseOneOption
 		phraseIndex = t.PhraseIndex
 		pwsati = &l.phrases[t.PhraseIndex]
 
@@ -390,20 +375,28 @@ func (l *Lesson) taskSolved(task app.PhraseLearningTask, success bool) {
 		} else if !t.IsInverted && !success {
 			ls.CountFailedOOS++
 		}
+
+		l.confusions.register(t, l.phraseAt, l.confusionsLog)
 	case *tranclateManuallyTask:
-		kindOfTask = kindOfTaskTranslateManually
+		kindOfTask = KindOfTaskTranslateManually
 		phraseIndex = t.PhraseIndex
 		pwsati = &l.phrases[t.PhraseIndex]
 
 		ls := &pwsati.LearningStatistics
 
+		almostCorrect := verdict == app.VerdictAlmostCorrect
+
 		if t.IsInverted && success {
 			ls.CountAnsweredTMInverted++
-		} else if t.IsInverted && !success {
+		} else if t.IsInverted && almostCorrect {
+			ls.CountAlmostTMInverted++
+		} else if t.IsInverted {
 			ls.CountFailedTMInverted++
-		} else if !t.IsInverted && success {
+		} else if success {
 			ls.CountAnsweredTM++
-		} else if !t.IsInverted && !success {
+		} else if almostCorrect {
+			ls.CountAlmostTM++
+		} else {
 			ls.CountFailedTM++
 		}
 	}
@@ -411,6 +404,21 @@ func (l *Lesson) taskSolved(task app.PhraseLearningTask, success bool) {
 	l.setWeightsToTasks(pwsati, kindOfTask, success)
 
 	l.updateLastPhrasesWeights(phraseIndex)
+
+	if l.progressListener != nil {
+		l.progressListener(
+			PhraseWithLearningStatistics{
+				Phrase:             pwsati.Phrase,
+				LearningStatistics: pwsati.LearningStatistics,
+			},
+		)
+	}
+}
+
+func (l *Lesson) taskReviewed(task lessonTask, attempt answerAttempt) {
+	if l.reviewsLog != nil {
+		l.reviewsLog(newReview(l.phrases[task.phraseIndex()].Phrase.Phrase, task, attempt))
+	}
 }
 
 func (l *Lesson) updateLastPhrasesWeights(updateStoredWeightsForPhrase int) {
@@ -440,12 +448,24 @@ func (l *Lesson) updateLastPhrasesWeights(updateStoredWeightsForPhrase int) {
 					newWeight = phraseTaskData.Weight / float64(i+1)
 				}
 
+				//Weights are derived from the valid ones, so errors are impossible.
 				l.tasksSelector.SetWeight(phraseTaskData.Index, newWeight)
 			}
 		}
 	}
 }
 
+// Recovers original weights of tasks connected with phrases from lastPhrasesToNotRepeat.
+func (l *Lesson) recoverLastPhrasesWeights() {
+	for _, phraseData := range l.lastPhrasesToNotRepeat {
+		if phraseData != nil {
+			for _, phraseTaskData := range phraseData.Tasks {
+				l.tasksSelector.SetWeight(phraseTaskData.Index, phraseTaskData.Weight)
+			}
+		}
+	}
+}
+
 // Updates array lastPhrasesToNotRepeat and decreases weights of tasks connected
 // with phrases from lastPhrasesToNotRepeat.
 func (l *Lesson) updateLastPhrases(currentPhraseIndex int) {
@@ -469,11 +489,14 @@ func (l *Lesson) updateLastPhrases(currentPhraseIndex int) {
 			Tasks:       make([]weightWithIndex, 0, 4),
 		}
 
-		for i, task := range l.tasksProperties {
-			if task.PhraseIndex != currentPhraseIndex {
-				continue
-			}
+		phrase := &l.phrases[currentPhraseIndex]
 
+		for _, i := range [...]int{
+			phrase.IndexOfChooseRightOptionTask,
+			phrase.IndexOfChooseRightOptionInvertedTask,
+			phrase.IndexOfTranslateManuallyTask,
+			phrase.IndexOfTranslateManuallyInvertedTask,
+		} {
 			newFirst.Tasks = append(
 				newFirst.Tasks,
 				weightWithIndex{
@@ -498,8 +521,30 @@ func (l *Lesson) updateLastPhrases(currentPhraseIndex int) {
 // Returns the next task. Can be called before check of previous task.
 // Avoids too often repetition of phrases.
 func (l *Lesson) Next(ctx context.Context) (app.PhraseLearningTask, error) {
+	if l.weightingErr != nil {
+		return nil, l.weightingErr
+	}
+
+	taskIndex, err := l.tasksSelector.TryGet()
+
+	if errors.Is(err, random.ErrZeroTotalWeight) {
+		//On tiny decks all the tasks with positive weights can belong
+		//to the last phrases, repetition of them is better than nothing.
+		l.recoverLastPhrasesWeights()
+
+		taskIndex, err = l.tasksSelector.TryGet()
+	}
+
+	if errors.Is(err, random.ErrZeroTotalWeight) {
+		return nil, app.ErrNoTasksAvailable
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
 	var (
-		taskProperties = l.tasksProperties[l.tasksSelector.Get()]
+		taskProperties = l.tasksProperties[taskIndex]
 		res            app.PhraseLearningTask
 	)
 
@@ -508,83 +553,60 @@ func (l *Lesson) Next(ctx context.Context) (app.PhraseLearningTask, error) {
 	defer l.updateLastPhrases(taskProperties.PhraseIndex)
 
 	switch taskProperties.KnidOfTask {
-	case kindOfTaskChooseOneOption:
-		optionsCount := 8
-
-		phrasesIndexes, err := l.getRandomPhrasesIndexes(optionsCount)
-
-		if err != nil {
-			return nil, err
-		}
-
-		var (
-			options     = make([]string, 0, optionsCount)
-			toTranslate string
-			right       = -1
+	case KindOfTaskChooseOneOption:
+		task, err := newOneOptionChoiceTask(
+			l.distractorSelector,
+			&l.distractors,
+			l.normalizers.forTask(taskProperties.Inverted),
+			taskProperties.PhraseIndex,
+			taskProperties.Inverted,
+			l.optionsCount,
+			l.taskSolved,
+			l.taskReviewed,
 		)
 
-		for i, index := range phrasesIndexes {
-			if index == taskProperties.PhraseIndex {
-				right = i
+		//There are no wrong options in tiny decks (or all the translations are the same),
+		//so the phrase is translated manually instead.
+		if errors.Is(err, app.ErrNotEnoughPhrasesInLesson) {
+			res = l.newTranslateManuallyTask(taskProperties.PhraseIndex, taskProperties.Inverted)
 
-				break
-			}
-		}
-
-		if right == -1 {
-			right = l.randSource.Intn(optionsCount)
-
-			phrasesIndexes[right] = taskProperties.PhraseIndex
+			break
 		}
 
-		for i, index := range phrasesIndexes {
-			toAdd := l.phrases[index].Phrase
-
-			if taskProperties.Inverted {
-				toAdd.Invert()
-			}
-
-			if i == right {
-				toTranslate = toAdd.Phrase
-			}
-
-			options = append(options, toAdd.Translation)
+		if err != nil {
+			return nil, err
 		}
 
-		res = &oneOptionChoiceTask{
-			PhraseToTranslate: toTranslate,
-			AvailableOptions:  options,
-			IsInverted:        taskProperties.Inverted,
-			RightAnswer:       right,
-			PhraseIndex:       phrasesIndexes[right],
-			Solved:            l.taskSolved,
-		}
+		res = task
 
-	case kindOfTaskTranslateManually:
-		//In case of kindOfTaskChooseOneOption this check implemented
-		//in the getRandomPhrasesIndexes().
+	case KindOfTaskTranslateManually:
+		//In case of KindOfTaskChooseOneOption this check implemented
+		//in the newOneOptionChoiceTask().
 		if len(l.phrases) <= 0 {
 			return nil, app.ErrNotEnoughPhrasesInLesson
 		}
 
-		phraseIndex := taskProperties.PhraseIndex
-		taskPhrase := l.phrases[phraseIndex].Phrase
-
-		if taskProperties.Inverted {
-			taskPhrase.Invert()
-		}
-
-		res = &tranclateManuallyTask{
-			PhraseToTranslate: taskPhrase,
-			IsInverted:        taskProperties.Inverted,
-			PhraseIndex:       phraseIndex,
-			Solved:            l.taskSolved,
-		}
+		res = l.newTranslateManuallyTask(taskProperties.PhraseIndex, taskProperties.Inverted)
 	}
 
 	return res, nil
 }
 
+func (l *Lesson) newTranslateManuallyTask(phraseIndex int, inverted bool) *tranclateManuallyTask {
+	return newTranslateManuallyTask(
+		l.phrases[phraseIndex].Phrase,
+		l.normalizers.forTask(inverted),
+		phraseIndex,
+		inverted,
+		l.taskSolved,
+		l.taskReviewed,
+	)
+}
+
+func (l *Lesson) phraseAt(i int) app.PhraseWithTranslation {
+	return l.phrases[i].Phrase
+}
+
 func (l *Lesson) GetProgress() []PhraseWithLearningStatistics {
 	res := make([]PhraseWithLearningStatistics, len(l.phrases))
 
@@ -0,0 +1,398 @@
+package advanced
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/random"
+)
+
+func testPhrases(count int) []app.PhraseWithTranslation {
+	phrases := make([]app.PhraseWithTranslation, count)
+
+	for i := range phrases {
+		phrases[i] = app.PhraseWithTranslation{
+			Phrase:      fmt.Sprint("phrase ", i),
+			Translation: fmt.Sprint("translation ", i),
+		}
+	}
+
+	return phrases
+}
+
+// Passes the lesson answering each task rightly and returns the description of all the tasks.
+// Right answers are found by the phrases of the lesson, so tasks aren't considered as failed.
+func passLesson(t *testing.T, lesson app.Lesson, phrases []app.PhraseWithTranslation, tasksCount int) []string {
+	rightAnswers := map[string]string{}
+
+	for _, phrase := range phrases {
+		rightAnswers[phrase.Phrase] = phrase.Translation
+		rightAnswers[phrase.Translation] = phrase.Phrase
+	}
+
+	res := make([]string, 0, tasksCount)
+
+	for range tasksCount {
+		task, err := lesson.Next(t.Context())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rightAnswer, found := rightAnswers[task.Phrase()]
+
+		if !found {
+			t.Fatal("unknown phrase of the task:", task.Phrase())
+		}
+
+		switch task := task.(type) {
+		case app.ChooseRightOption:
+			res = append(res, fmt.Sprint("choice ", task.Phrase(), " ", task.Inverted(), " ", task.Options()))
+
+			right, err := task.Right(t.Context(), slices.Index(task.Options(), rightAnswer))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !right {
+				t.Fatal("the right option wasn't found:", rightAnswer, task.Options())
+			}
+		case app.TranslateManually:
+			res = append(res, fmt.Sprint("manual ", task.Phrase(), " ", task.Inverted()))
+
+			verdict, err := task.Right(t.Context(), rightAnswer)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if verdict != app.VerdictCorrect {
+				t.Fatal("the right translation wasn't accepted:", rightAnswer)
+			}
+		}
+	}
+
+	return res
+}
+
+func TestSameSeedReplaysLesson(t *testing.T) {
+	var sessions [2][]string
+
+	for i := range sessions {
+		lesson, err := New(testPhrases(30), false, WithSeed(42))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seed, known := lesson.Seed()
+
+		if !known || seed != 42 {
+			t.Fatal("wrong seed of the lesson:", seed, known)
+		}
+
+		sessions[i] = passLesson(t, lesson, testPhrases(30), 200)
+	}
+
+	if !slices.Equal(sessions[0], sessions[1]) {
+		t.Fatal("lessons with the same seed gave different tasks")
+	}
+}
+
+func TestGeneratedSeedReplaysLesson(t *testing.T) {
+	lesson, err := New(testPhrases(30), false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed, known := lesson.Seed()
+
+	if !known {
+		t.Fatal("generated seed should be known")
+	}
+
+	replay, err := New(testPhrases(30), false, WithSeed(seed))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(passLesson(t, lesson, testPhrases(30), 200), passLesson(t, replay, testPhrases(30), 200)) {
+		t.Fatal("replay of the lesson gave different tasks")
+	}
+}
+
+// Tasks of all the stages (inverted choice and manual translation too) are replayed.
+func TestReplayReachesAllStages(t *testing.T) {
+	var sessions [2][]string
+
+	for i := range sessions {
+		lesson, err := New(testPhrases(5), false, WithSeed(7))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sessions[i] = passLesson(t, lesson, testPhrases(5), 300)
+	}
+
+	if !slices.Equal(sessions[0], sessions[1]) {
+		t.Fatal("lessons with the same seed gave different tasks")
+	}
+
+	for _, prefix := range []string{"choice phrase", "choice translation", "manual phrase", "manual translation"} {
+		if !slices.ContainsFunc(sessions[0], func(task string) bool { return strings.HasPrefix(task, prefix) }) {
+			t.Fatalf("tasks %q weren't given", prefix)
+		}
+	}
+}
+
+// A deck of 50k phrases (200k tasks) should stay responsive.
+func BenchmarkLargeLesson(b *testing.B) {
+	lesson, err := New(testPhrases(50000), false, WithSeed(0))
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		task, err := lesson.Next(b.Context())
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		switch task := task.(type) {
+		case app.ChooseRightOption:
+			task.Right(b.Context(), 0)
+		case app.TranslateManually:
+			task.Right(b.Context(), "")
+		}
+	}
+}
+
+func TestSpellingOnlyTinyDeck(t *testing.T) {
+	lesson, err := New(testPhrases(1), true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 10 {
+		task, err := lesson.Next(t.Context())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := task.(app.TranslateManually); !ok || !task.Inverted() {
+			t.Fatal("only inverted manual translation tasks are expected in spelling mode")
+		}
+	}
+}
+
+func TestTinyDecks(t *testing.T) {
+	sameTranslations := testPhrases(2)
+
+	sameTranslations[1].Translation = strings.ToUpper(sameTranslations[0].Translation)
+
+	for _, phrases := range [][]app.PhraseWithTranslation{testPhrases(1), sameTranslations} {
+		lesson, err := New(phrases, false)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		srsPhrases := make([]PhraseWithSchedule, len(phrases))
+
+		for i := range phrases {
+			srsPhrases[i].Phrase = phrases[i]
+		}
+
+		srsLesson, err := NewSpacedRepetition(srsPhrases)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		//Without wrong options phrases are translated manually instead of choice of options.
+		for _, lesson := range []app.Lesson{lesson, srsLesson} {
+			task, err := lesson.Next(t.Context())
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := task.(app.TranslateManually); !ok {
+				t.Fatalf("manual translation is expected, got %T", task)
+			}
+		}
+	}
+}
+
+// Returns the same weight for all the tasks.
+type constantWeighting float64
+
+func (w constantWeighting) WeightOfTask(*PhraseLearningStatistics, KindOfTask, bool, bool) float64 {
+	return float64(w)
+}
+
+func TestInvalidWeighting(t *testing.T) {
+	_, err := New(testPhrases(10), false, WithWeightingStrategy(constantWeighting(-1)))
+
+	if !errors.Is(err, random.ErrNegativeWeight) {
+		t.Fatal("ErrNegativeWeight should be returned, got:", err)
+	}
+
+	lesson, err := New(testPhrases(10), false, WithWeightingStrategy(constantWeighting(0)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = lesson.Next(t.Context())
+
+	if !errors.Is(err, app.ErrNoTasksAvailable) {
+		t.Fatal("ErrNoTasksAvailable should be returned, got:", err)
+	}
+}
+
+func testOptionsCount(t *testing.T, phrasesCount int, expected int, opts ...Option) {
+	lesson, err := New(testPhrases(phrasesCount), false, opts...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//First tasks of new phrases are always choice tasks.
+	task, err := lesson.Next(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := task.(app.ChooseRightOption).Options()
+
+	if len(options) != expected {
+		t.Fatalf("%d options expected for %d phrases, got %d", expected, phrasesCount, len(options))
+	}
+}
+
+func TestOptionsCount(t *testing.T) {
+	testOptionsCount(t, 30, OPTIONS_COUNT)
+	testOptionsCount(t, 3, 3)
+	testOptionsCount(t, 2, 2)
+	testOptionsCount(t, 30, 4, WithOptionsCount(4))
+	testOptionsCount(t, 30, MAX_OPTIONS_COUNT, WithOptionsCount(100))
+	testOptionsCount(t, 30, MIN_OPTIONS_COUNT, WithOptionsCount(0))
+	testOptionsCount(t, 5, 5, WithOptionsCount(10))
+}
+
+func TestSharedKnowledge(t *testing.T) {
+	phrases := []PhraseWithLearningStatistics{
+		{Phrase: app.PhraseWithTranslation{Phrase: "phrase 0", Translation: "translation 0"}, LearningStatistics: PhraseLearningStatistics{CountGuessedOOS: 1}},
+		{Phrase: app.PhraseWithTranslation{Phrase: "phrase 1", Translation: "translation 1"}},
+	}
+
+	knowledge := map[KnowledgeKey]PhraseLearningStatistics{}
+
+	for i := range phrases {
+		knowledge[NewKnowledgeKey(&phrases[i].Phrase)] = PhraseLearningStatistics{CountGuessedOOS: 10}
+	}
+
+	firstChanges := map[string]PhraseLearningStatistics{}
+
+	lesson, err := NewWithProgress(
+		phrases,
+		false,
+		WithSharedKnowledge(knowledge),
+		WithProgressListener(func(changed PhraseWithLearningStatistics) {
+			if _, found := firstChanges[changed.Phrase.Phrase]; !found {
+				firstChanges[changed.Phrase.Phrase] = changed.LearningStatistics
+			}
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for len(firstChanges) < len(phrases) {
+		passLesson(t, lesson, []app.PhraseWithTranslation{phrases[0].Phrase, phrases[1].Phrase}, 1)
+	}
+
+	if firstChanges["phrase 0"].CountGuessedOOS >= 10 {
+		t.Fatal("own statistics of a phrase shouldn't be replaced:", firstChanges["phrase 0"])
+	}
+
+	if firstChanges["phrase 1"].CountGuessedOOS < 10 {
+		t.Fatal("statistics of a new phrase should begin from shared ones:", firstChanges["phrase 1"])
+	}
+}
+
+func TestSpellingWeighting(t *testing.T) {
+	weighting := DefaultWeighting()
+
+	spellingWeight := func(stats PhraseLearningStatistics) float64 {
+		return weighting.WeightOfTask(&stats, KindOfTaskTranslateManually, true, true)
+	}
+
+	var (
+		newPhrase   = spellingWeight(PhraseLearningStatistics{})
+		misspelled  = spellingWeight(PhraseLearningStatistics{CountFailedTMInverted: 3, CountAnsweredTMInverted: 1})
+		withTypos   = spellingWeight(PhraseLearningStatistics{CountFailedTMInverted: 1, CountAlmostTMInverted: 4})
+		wellSpelled = spellingWeight(PhraseLearningStatistics{CountAnsweredTMInverted: 100})
+	)
+
+	if !(misspelled > withTypos && withTypos > newPhrase && newPhrase > wellSpelled) {
+		t.Fatal("misspelled phrases should be prioritized:", misspelled, withTypos, newPhrase, wellSpelled)
+	}
+
+	if wellSpelled < weighting.LearnedPhraseWeight {
+		t.Fatal("weight of a well spelled phrase shouldn't be less than LearnedPhraseWeight:", wellSpelled)
+	}
+
+	stats := PhraseLearningStatistics{CountFailedTMInverted: 3}
+
+	if weighting.WeightOfTask(&stats, KindOfTaskChooseOneOption, true, true) != 0 {
+		t.Fatal("only inverted manual translation tasks are expected in spelling mode")
+	}
+}
+
+func TestSpellingProgress(t *testing.T) {
+	phrases := make([]PhraseWithLearningStatistics, 3)
+
+	for i, phrase := range testPhrases(len(phrases)) {
+		phrases[i] = PhraseWithLearningStatistics{
+			Phrase:             phrase,
+			LearningStatistics: PhraseLearningStatistics{CountAnsweredTMInverted: 2, CountGuessedOOS: 5},
+		}
+	}
+
+	var changed PhraseWithLearningStatistics
+
+	lesson, err := NewWithProgress(
+		phrases,
+		true,
+		WithProgressListener(func(phraseWithStats PhraseWithLearningStatistics) {
+			changed = phraseWithStats
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passLesson(t, lesson, testPhrases(len(phrases)), 1)
+
+	expected := PhraseLearningStatistics{CountAnsweredTMInverted: 3, CountGuessedOOS: 5}
+
+	if changed.LearningStatistics != expected {
+		t.Fatalf("expected statistics %+v, got %+v", expected, changed.LearningStatistics)
+	}
+}
@@ -2,7 +2,6 @@ package advanced
 
 import (
 	"context"
-	"strings"
 	"vocabulary/internal/app"
 )
 
@@ -10,23 +9,69 @@ type tranclateManuallyTask struct {
 	PhraseToTranslate app.PhraseWithTranslation
 	IsInverted        bool
 	PhraseIndex       int
-	Solved            func(app.PhraseLearningTask, bool)
+	Normalizer        *normalizer
+	Solved            func(app.PhraseLearningTask, app.Verdict)
+	Reviewed          func(lessonTask, answerAttempt)
 
 	alreadyAnswered bool
 }
 
-var _ app.TranslateManually = (*tranclateManuallyTask)(nil)
+var (
+	_ app.TranslateManually = (*tranclateManuallyTask)(nil)
+	_ lessonTask            = (*tranclateManuallyTask)(nil)
+)
+
+// Returns the task of translation of the phrase (of the phrase itself if inverted is true).
+func newTranslateManuallyTask(
+	phrase app.PhraseWithTranslation,
+	n *normalizer,
+	phraseIndex int,
+	inverted bool,
+	solved func(app.PhraseLearningTask, app.Verdict),
+	reviewed func(lessonTask, answerAttempt),
+) *tranclateManuallyTask {
+	if inverted {
+		phrase.Invert()
+	}
+
+	return &tranclateManuallyTask{
+		PhraseToTranslate: phrase,
+		IsInverted:        inverted,
+		PhraseIndex:       phraseIndex,
+		Normalizer:        n,
+		Solved:            solved,
+		Reviewed:          reviewed,
+	}
+}
+
+func (t *tranclateManuallyTask) phraseIndex() int {
+	return t.PhraseIndex
+}
+
+func (t *tranclateManuallyTask) kindOfTask() KindOfTask {
+	return KindOfTaskTranslateManually
+}
 
 func (t *tranclateManuallyTask) GetRightAnswer(context.Context) (string, error) {
+	t.Reviewed(t, answerAttempt{RightAnswerRequested: true})
+
 	if !t.alreadyAnswered {
 		t.alreadyAnswered = true
 
-		t.Solved(t, false)
+		t.Solved(t, app.VerdictWrong)
 	}
 
 	return t.PhraseToTranslate.Translation, nil
 }
 
+func (t *tranclateManuallyTask) RightTranslation() string {
+	if !t.alreadyAnswered {
+		return ""
+	}
+
+	return t.PhraseToTranslate.Translation
+}
+
 func (t *tranclateManuallyTask) Inverted() bool {
 	return t.IsInverted
 }
@@ -35,24 +80,23 @@ func (t *tranclateManuallyTask) Phrase() string {
 	return t.PhraseToTranslate.Phrase
 }
 
-func (t *tranclateManuallyTask) Right(_ context.Context, translation string) (bool, error) {
-	toCompareWith := t.PhraseToTranslate.Translation
-
-	for replaceWhat, replaceFor := range replacement {
-		translation = strings.ReplaceAll(translation, string(replaceWhat), string(replaceFor))
-		toCompareWith = strings.ReplaceAll(toCompareWith, string(replaceWhat), string(replaceFor))
-	}
-
-	translation = strings.TrimSpace(translation)
-	toCompareWith = strings.TrimSpace(toCompareWith)
+func (t *tranclateManuallyTask) Right(_ context.Context, translation string) (app.Verdict, error) {
+	verdict := checkAnswerVariants(translation, t.PhraseToTranslate.AcceptedTranslations(), t.Normalizer)
 
-	answerIsCorrect := strings.EqualFold(translation, toCompareWith)
+	t.Reviewed(
+		t,
+		answerAttempt{
+			Correct:       verdict == app.VerdictCorrect,
+			AlmostCorrect: verdict == app.VerdictAlmostCorrect,
+			Answer:        translation,
+		},
+	)
 
 	if !t.alreadyAnswered {
 		t.alreadyAnswered = true
 
-		t.Solved(t, answerIsCorrect)
+		t.Solved(t, verdict)
 	}
 
-	return answerIsCorrect, nil
+	return verdict, nil
 }
@@ -0,0 +1,138 @@
+package advanced
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Settings of comparison of manually typed translations with the right ones.
+type Normalization struct {
+	//Letters with diacritics are equal to letters without them ("école" and "ecole").
+	IgnoreDiacritics bool
+
+	//ISO 639-1 codes of languages of phrases and translations ("en", "ru", "de", ...).
+	//Define language specific equivalences of letters ("ё" and "е" in Russian).
+	//Can be empty.
+	PhraseLanguage, TranslationLanguage string
+
+	//Words which are ignored at the beginning of answers (like articles and "to" of infinitives)
+	//by language code. Lists of defaultIgnorableLeadingWords are used for languages absent here.
+	IgnorableLeadingWords map[string][]string
+}
+
+// Brings answers to the form in which they can be compared:
+//   - composed and decomposed characters are equal (NFC);
+//   - typographic quotes are equal to simple ones;
+//   - case is ignored;
+//   - language specific equivalences are applied;
+//   - diacritics are removed (optionally);
+//   - punctuation (excluding apostrophes and hyphens) is ignored,
+//     sequences of spaces are equal to one space;
+//   - ignorable words at the beginning are removed.
+type normalizer struct {
+	languageEquivalences  *strings.Replacer
+	ignoreDiacritics      bool
+	ignorableLeadingWords map[string]struct{}
+}
+
+func newNormalizer(language string, normalization *Normalization) *normalizer {
+	language = strings.ToLower(language)
+
+	res := &normalizer{
+		ignoreDiacritics:      normalization.IgnoreDiacritics,
+		ignorableLeadingWords: map[string]struct{}{},
+	}
+
+	equivalences, found := languageEquivalences[language]
+
+	if found {
+		res.languageEquivalences = strings.NewReplacer(equivalences...)
+	}
+
+	ignorableLeadingWords, found := normalization.IgnorableLeadingWords[language]
+
+	if !found {
+		ignorableLeadingWords = defaultIgnorableLeadingWords[language]
+	}
+
+	for _, word := range ignorableLeadingWords {
+		res.ignorableLeadingWords[strings.ToLower(word)] = struct{}{}
+	}
+
+	return res
+}
+
+func (n *normalizer) Normalize(s string) string {
+	s = norm.NFC.String(s)
+
+	for replaceWhat, replaceFor := range replacement {
+		s = strings.ReplaceAll(s, string(replaceWhat), string(replaceFor))
+	}
+
+	s = strings.ToLower(s)
+
+	if n.languageEquivalences != nil {
+		s = n.languageEquivalences.Replace(s)
+	}
+
+	if n.ignoreDiacritics {
+		withoutDiacritics, _, err := transform.String(
+			transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC),
+			s,
+		)
+
+		if err == nil {
+			s = withoutDiacritics
+		}
+	}
+
+	s = strings.Map(
+		func(r rune) rune {
+			if unicode.IsPunct(r) && r != '\'' && r != '-' {
+				return ' '
+			}
+
+			return r
+		},
+		s,
+	)
+
+	words := strings.Fields(s)
+
+	//The last word is never removed: "the" can be a translation itself.
+	for len(words) > 1 {
+		_, ignorable := n.ignorableLeadingWords[words[0]]
+
+		if !ignorable {
+			break
+		}
+
+		words = words[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Normalizers of answers of direct tasks (translations) and inverted ones (phrases).
+type normalizers struct {
+	phrase, translation *normalizer
+}
+
+func newNormalizers(normalization Normalization) normalizers {
+	return normalizers{
+		phrase:      newNormalizer(normalization.PhraseLanguage, &normalization),
+		translation: newNormalizer(normalization.TranslationLanguage, &normalization),
+	}
+}
+
+func (n *normalizers) forTask(inverted bool) *normalizer {
+	if inverted {
+		return n.phrase
+	}
+
+	return n.translation
+}
@@ -0,0 +1,166 @@
+package advanced
+
+import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand"
+	"vocabulary/internal/app"
+)
+
+// Optional parameters of lessons.
+type Option func(*settings)
+
+type settings struct {
+	weighting        WeightingStrategy
+	reviewsLog       ReviewsLog
+	progressListener ProgressListener
+	normalization    Normalization
+
+	distractorSelector DistractorSelector
+	optionsCount       int
+	confusions         []app.Confusion
+	confusionsLog      ConfusionsLog
+
+	knowledge map[KnowledgeKey]PhraseLearningStatistics
+
+	//If randSource is nil, it is created with seed
+	//(generated randomly if seedKnown is false).
+	randSource *mathrand.Rand
+	seed       int64
+	seedKnown  bool
+}
+
+func newSettings(opts []Option) settings {
+	res := settings{
+		weighting:          DefaultWeighting(),
+		distractorSelector: DefaultSimilarDistractors(),
+		optionsCount:       OPTIONS_COUNT,
+	}
+
+	for _, opt := range opts {
+		opt(&res)
+	}
+
+	return res
+}
+
+// Sets the strategy of prioritizing tasks of Lesson (DefaultWeighting() by default).
+func WithWeightingStrategy(weighting WeightingStrategy) Option {
+	return func(s *settings) {
+		if weighting != nil {
+			s.weighting = weighting
+		}
+	}
+}
+
+// Sets the function called on each answer to tasks of the lesson.
+func WithReviewsLog(reviewsLog ReviewsLog) Option {
+	return func(s *settings) {
+		s.reviewsLog = reviewsLog
+	}
+}
+
+// Sets the function called by Lesson after each change of phrase statistics.
+func WithProgressListener(progressListener ProgressListener) Option {
+	return func(s *settings) {
+		s.progressListener = progressListener
+	}
+}
+
+// Sets statistics of phrases gathered in all the lessons. NewWithProgress uses them
+// for phrases which have no statistics of their own. It should be set only for lessons
+// without stored history, otherwise phrases which weren't answered yet would skip stages.
+func WithSharedKnowledge(knowledge map[KnowledgeKey]PhraseLearningStatistics) Option {
+	return func(s *settings) {
+		s.knowledge = knowledge
+	}
+}
+
+// Sets the rules of comparison of manually typed translations with the right ones.
+func WithNormalization(normalization Normalization) Option {
+	return func(s *settings) {
+		s.normalization = normalization
+	}
+}
+
+// Sets the seed of random numbers generator of the lesson to make the order of tasks reproducible.
+// By default the seed is generated by a cryptographically secure generator.
+func WithSeed(seed int64) Option {
+	return func(s *settings) {
+		s.randSource = nil
+		s.seed = seed
+		s.seedKnown = true
+	}
+}
+
+// Sets random numbers generator of the lesson. The seed of the lesson becomes unknown.
+func WithRandSource(randSource *mathrand.Rand) Option {
+	return func(s *settings) {
+		s.randSource = randSource
+		s.seedKnown = false
+	}
+}
+
+// Returns a pseudo-random numbers generator of the lesson.
+// Generates the seed by a cryptographically secure generator if it is not set.
+func (s *settings) newRandSource() (*mathrand.Rand, error) {
+	if s.randSource != nil {
+		return s.randSource, nil
+	}
+
+	if !s.seedKnown {
+		randomKey := make([]byte, 8)
+
+		cryptorand.Read(randomKey)
+
+		err := binary.Read(bytes.NewReader(randomKey), binary.BigEndian, &s.seed)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.seedKnown = true
+	}
+
+	return mathrand.New(mathrand.NewSource(s.seed)), nil
+}
+
+// Sets the way of choice of wrong options of choice tasks (DefaultSimilarDistractors() by default).
+// RandomDistractors{} chooses them randomly.
+func WithDistractorSelector(distractorSelector DistractorSelector) Option {
+	return func(s *settings) {
+		if distractorSelector != nil {
+			s.distractorSelector = distractorSelector
+		}
+	}
+}
+
+// Sets confusions of phrases made by the user earlier
+// (confused phrases are paired in choice tasks).
+func WithConfusions(confusions []app.Confusion) Option {
+	return func(s *settings) {
+		s.confusions = confusions
+	}
+}
+
+// Sets the function called on each confusion of phrases in choice tasks.
+func WithConfusionsLog(confusionsLog ConfusionsLog) Option {
+	return func(s *settings) {
+		s.confusionsLog = confusionsLog
+	}
+}
+
+// Sets the count of options in choice tasks (OPTIONS_COUNT by default).
+// The count is limited by MIN_OPTIONS_COUNT and MAX_OPTIONS_COUNT.
+func WithOptionsCount(optionsCount int) Option {
+	return func(s *settings) {
+		s.optionsCount = min(max(optionsCount, MIN_OPTIONS_COUNT), MAX_OPTIONS_COUNT)
+	}
+}
+
+// Returns the count of options in choice tasks of a lesson with phrasesCount phrases:
+// small lessons have less options than it is set (but not less than MIN_OPTIONS_COUNT).
+func (s *settings) optionsCountFor(phrasesCount int) int {
+	return max(min(s.optionsCount, phrasesCount), MIN_OPTIONS_COUNT)
+}
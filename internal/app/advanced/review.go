@@ -0,0 +1,57 @@
+package advanced
+
+import (
+	"time"
+	"vocabulary/internal/app"
+)
+
+// A record about an answer to a task or a request of its' right answer.
+type Review struct {
+	//The phrase of the lesson (doesn't depend on task inversion).
+	Phrase     string
+	KindOfTask KindOfTask
+	Inverted   bool
+	Correct    bool
+
+	//Manual translation with a few typos.
+	AlmostCorrect bool
+
+	//Typed translation or the text of chosen option.
+	//Empty when the right answer was requested.
+	Answer               string
+	RightAnswerRequested bool
+
+	TimeUTC time.Time
+}
+
+// Called on each answer to a task (not only the first one).
+type ReviewsLog func(Review)
+
+// Data about an answer passed by tasks to lessons.
+type answerAttempt struct {
+	Correct              bool
+	AlmostCorrect        bool
+	Answer               string
+	RightAnswerRequested bool
+}
+
+// Implemented by all the tasks of the package.
+type lessonTask interface {
+	app.PhraseLearningTask
+
+	phraseIndex() int
+	kindOfTask() KindOfTask
+}
+
+func newReview(phrase string, task lessonTask, attempt answerAttempt) Review {
+	return Review{
+		Phrase:               phrase,
+		KindOfTask:           task.kindOfTask(),
+		Inverted:             task.Inverted(),
+		Correct:              attempt.Correct,
+		AlmostCorrect:        attempt.AlmostCorrect,
+		Answer:               attempt.Answer,
+		RightAnswerRequested: attempt.RightAnswerRequested,
+		TimeUTC:              time.Now().UTC(),
+	}
+}
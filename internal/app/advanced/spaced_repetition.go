@@ -0,0 +1,306 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	mathrand "math/rand"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/random"
+)
+
+// State of memorization of a phrase used by SpacedRepetitionLesson.
+type PhraseSchedule struct {
+	//The period (in days) during which the phrase is expected to be remembered.
+	//Zero for phrases which were never answered right.
+	Stability float64
+
+	//From 0 (the easiest) to 1 (the hardest). Defines how fast stability grows.
+	Difficulty float64
+
+	//The moment when the phrase should be repeated.
+	Due time.Time
+
+	LastReview time.Time
+	Reviews    uint32
+	Lapses     uint32
+}
+
+func (s *PhraseSchedule) IsNew() bool {
+	return s.Reviews == 0
+}
+
+type PhraseWithSchedule struct {
+	Phrase   app.PhraseWithTranslation
+	Schedule PhraseSchedule
+}
+
+// The implementation of app.Lesson based on spaced repetition (SM-2 like algorithm).
+// Unlike Lesson, it serves only phrases which are due to be repeated
+// and a limited count of new phrases. Returns app.ErrNoPhrasesToRepeat
+// when all the phrases are repeated.
+//
+// Method reschedule() contains the algorithm of scheduling.
+type SpacedRepetitionLesson struct {
+	phrases    []PhraseWithSchedule
+	randSource *mathrand.Rand
+
+	//Wrong options of choice tasks (all the phrases have the same weight).
+	distractors        DistractorsSource
+	distractorSelector DistractorSelector
+	confusions         confusions
+	confusionsLog      ConfusionsLog
+
+	//Count of options in choice tasks (adapted to the count of phrases).
+	optionsCount int
+
+	//Count of new phrases which can be shown during the rest of lesson.
+	newPhrasesLeft int
+
+	//Index of the phrase of the previous task (-1 at the beginning of lesson).
+	//Used to avoid repetition of one phrase twice in a row.
+	lastPhrase int
+
+	reviewsLog  ReviewsLog
+	normalizers normalizers
+
+	seed      int64
+	seedKnown bool
+}
+
+var _ app.Lesson = (*SpacedRepetitionLesson)(nil)
+
+func NewSpacedRepetition(phrases []PhraseWithSchedule, opts ...Option) (*SpacedRepetitionLesson, error) {
+	if len(phrases) <= 0 {
+		return nil, app.ErrNotEnoughPhrasesInLesson
+	}
+
+	settings := newSettings(opts)
+
+	randSource, err := settings.newRandSource()
+
+	if err != nil {
+		return nil, err
+	}
+
+	distractorsWeights := make([]float64, len(phrases))
+
+	for i := range distractorsWeights {
+		distractorsWeights[i] = 1
+	}
+
+	distractorsSampler, err := random.NewDiscreteRandomVariable(randSource, distractorsWeights)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SpacedRepetitionLesson{
+		phrases:            make([]PhraseWithSchedule, len(phrases)),
+		randSource:         randSource,
+		distractorSelector: settings.distractorSelector,
+		confusions:         confusions{},
+		confusionsLog:      settings.confusionsLog,
+		optionsCount:       settings.optionsCountFor(len(phrases)),
+		newPhrasesLeft:     SRS_NEW_PHRASES_PER_LESSON,
+		lastPhrase:         -1,
+		reviewsLog:         settings.reviewsLog,
+		normalizers:        newNormalizers(settings.normalization),
+		seed:               settings.seed,
+		seedKnown:          settings.seedKnown,
+	}
+
+	res.distractors = DistractorsSource{
+		PhrasesCount: len(phrases),
+		PhraseAt:     res.phraseAt,
+		RandSource:   randSource,
+		Sampler:      distractorsSampler,
+		Confusions:   res.confusions.of,
+	}
+
+	copy(res.phrases, phrases)
+
+	res.confusions.load(settings.confusions, res.distractors.PhrasesCount, res.phraseAt)
+
+	for i := range res.phrases {
+		schedule := &res.phrases[i].Schedule
+
+		if schedule.IsNew() {
+			schedule.Difficulty = SRS_INITIAL_DIFFICULTY
+		}
+	}
+
+	return res, nil
+}
+
+// Returns index of the phrase which should be repeated now or -1.
+// Phrases with the earliest due moment are preferred.
+func (l *SpacedRepetitionLesson) findDuePhrase(now time.Time) int {
+	var (
+		res = -1
+
+		//The phrase of the previous task is returned only
+		//if there are no other phrases to repeat.
+		lastPhraseIsDue = false
+	)
+
+	for i := range l.phrases {
+		schedule := &l.phrases[i].Schedule
+
+		if schedule.IsNew() || schedule.Due.After(now) {
+			continue
+		}
+
+		if i == l.lastPhrase {
+			lastPhraseIsDue = true
+
+			continue
+		}
+
+		if res == -1 || schedule.Due.Before(l.phrases[res].Schedule.Due) {
+			res = i
+		}
+	}
+
+	if res == -1 && lastPhraseIsDue {
+		return l.lastPhrase
+	}
+
+	return res
+}
+
+func (l *SpacedRepetitionLesson) findNewPhrase() int {
+	if l.newPhrasesLeft <= 0 {
+		return -1
+	}
+
+	for i := range l.phrases {
+		if l.phrases[i].Schedule.IsNew() && i != l.lastPhrase {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Returns the next task. Can be called before check of previous task.
+// First two reviews of a phrase are choice of the right option
+// (direct and inverted), further reviews are manual translation.
+func (l *SpacedRepetitionLesson) Next(ctx context.Context) (app.PhraseLearningTask, error) {
+	phraseIndex := l.findDuePhrase(time.Now())
+
+	if phraseIndex == -1 {
+		phraseIndex = l.findNewPhrase()
+
+		if phraseIndex == -1 {
+			return nil, app.ErrNoPhrasesToRepeat
+		}
+
+		l.newPhrasesLeft--
+	}
+
+	l.lastPhrase = phraseIndex
+
+	schedule := &l.phrases[phraseIndex].Schedule
+
+	inverted := schedule.Reviews%2 == 1
+
+	if schedule.Reviews < 2 {
+		task, err := newOneOptionChoiceTask(
+			l.distractorSelector,
+			&l.distractors,
+			l.normalizers.forTask(inverted),
+			phraseIndex,
+			inverted,
+			l.optionsCount,
+			l.taskSolved,
+			l.taskReviewed,
+		)
+
+		//Like in Lesson, phrases of tiny decks are translated manually instead.
+		if !errors.Is(err, app.ErrNotEnoughPhrasesInLesson) {
+			return task, err
+		}
+	}
+
+	return newTranslateManuallyTask(
+		l.phrases[phraseIndex].Phrase,
+		l.normalizers.forTask(inverted),
+		phraseIndex,
+		inverted,
+		l.taskSolved,
+		l.taskReviewed,
+	), nil
+}
+
+func (l *SpacedRepetitionLesson) phraseAt(i int) app.PhraseWithTranslation {
+	return l.phrases[i].Phrase
+}
+
+func (l *SpacedRepetitionLesson) taskSolved(task app.PhraseLearningTask, verdict app.Verdict) {
+	t, ok := task.(lessonTask)
+
+	if !ok {
+		return
+	}
+
+	if choice, ok := t.(*oneOptionChoiceTask); ok {
+		l.confusions.register(choice, l.phraseAt, l.confusionsLog)
+	}
+
+	reschedule(&l.phrases[t.phraseIndex()].Schedule, verdict, time.Now())
+}
+
+func (l *SpacedRepetitionLesson) taskReviewed(task lessonTask, attempt answerAttempt) {
+	if l.reviewsLog != nil {
+		l.reviewsLog(newReview(l.phrases[task.phraseIndex()].Phrase.Phrase, task, attempt))
+	}
+}
+
+// Contains the algorithm of scheduling: updates the state of memorization
+// of a phrase after an answer and calculates the moment of the next repetition.
+// Almost correct answers don't make the phrase easier and cause the minimal growth of stability.
+func reschedule(schedule *PhraseSchedule, verdict app.Verdict, now time.Time) {
+	if verdict != app.VerdictWrong {
+		growth := SRS_MIN_STABILITY_GROWTH
+
+		if verdict == app.VerdictCorrect {
+			schedule.Difficulty = max(schedule.Difficulty-SRS_DIFFICULTY_DECREASE_ON_SUCCESS, 0)
+
+			growth += (SRS_MAX_STABILITY_GROWTH - SRS_MIN_STABILITY_GROWTH) * (1 - schedule.Difficulty)
+		}
+
+		if schedule.Stability <= 0 {
+			schedule.Stability = SRS_FIRST_STABILITY
+		} else {
+			schedule.Stability *= growth
+		}
+
+		schedule.Due = now.Add(time.Duration(schedule.Stability * float64(time.Hour*24)))
+	} else {
+		if !schedule.IsNew() {
+			schedule.Lapses++
+		}
+
+		schedule.Difficulty = min(schedule.Difficulty+SRS_DIFFICULTY_INCREASE_ON_FAILURE, 1)
+		schedule.Stability *= SRS_STABILITY_REMAINDER_ON_FAILURE
+		schedule.Due = now.Add(SRS_RELEARNING_DELAY)
+	}
+
+	schedule.Reviews++
+	schedule.LastReview = now
+}
+
+// Returns the seed of random numbers generator of the lesson (see Lesson.Seed()).
+// Notice that order of tasks depends on time too.
+func (l *SpacedRepetitionLesson) Seed() (seed int64, known bool) {
+	return l.seed, l.seedKnown
+}
+
+func (l *SpacedRepetitionLesson) GetProgress() []PhraseWithSchedule {
+	res := make([]PhraseWithSchedule, len(l.phrases))
+
+	copy(res, l.phrases)
+
+	return res
+}
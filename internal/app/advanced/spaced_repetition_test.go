@@ -0,0 +1,234 @@
+package advanced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+	"vocabulary/internal/app"
+)
+
+func TestReschedule(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, testCase := range []struct {
+		name     string
+		schedule PhraseSchedule
+		verdict  app.Verdict
+		expected PhraseSchedule
+	}{
+		{
+			"first right answer",
+			PhraseSchedule{Difficulty: SRS_INITIAL_DIFFICULTY},
+			app.VerdictCorrect,
+			PhraseSchedule{Stability: 1, Difficulty: 0.25, Due: now.Add(time.Hour * 24), Reviews: 1},
+		},
+		{
+			"right answer",
+			PhraseSchedule{Stability: 1, Difficulty: 0.25, Reviews: 1},
+			app.VerdictCorrect,
+			PhraseSchedule{Stability: 2.66, Difficulty: 0.2, Due: now.Add(time.Duration(2.66 * float64(time.Hour*24))), Reviews: 2},
+		},
+		{
+			"almost right answer",
+			PhraseSchedule{Stability: 2, Difficulty: 0.5, Reviews: 2},
+			app.VerdictAlmostCorrect,
+			PhraseSchedule{Stability: 2.6, Difficulty: 0.5, Due: now.Add(time.Duration(2.6 * float64(time.Hour*24))), Reviews: 3},
+		},
+		{
+			"lapse",
+			PhraseSchedule{Stability: 10, Difficulty: 0.5, Reviews: 3},
+			app.VerdictWrong,
+			PhraseSchedule{Stability: 2, Difficulty: 0.65, Due: now.Add(SRS_RELEARNING_DELAY), Reviews: 4, Lapses: 1},
+		},
+		{
+			"wrong first answer isn't a lapse",
+			PhraseSchedule{Difficulty: SRS_INITIAL_DIFFICULTY},
+			app.VerdictWrong,
+			PhraseSchedule{Difficulty: 0.45, Due: now.Add(SRS_RELEARNING_DELAY), Reviews: 1},
+		},
+		{
+			"difficulty is limited",
+			PhraseSchedule{Stability: 1, Difficulty: 0.95, Reviews: 5, Lapses: 2},
+			app.VerdictWrong,
+			PhraseSchedule{Stability: 0.2, Difficulty: 1, Due: now.Add(SRS_RELEARNING_DELAY), Reviews: 6, Lapses: 3},
+		},
+	} {
+		schedule := testCase.schedule
+
+		reschedule(&schedule, testCase.verdict, now)
+
+		testCase.expected.LastReview = now
+
+		if math.Abs(schedule.Stability-testCase.expected.Stability) > 1e-9 ||
+			math.Abs(schedule.Difficulty-testCase.expected.Difficulty) > 1e-9 ||
+			schedule.Due.Sub(testCase.expected.Due).Abs() > time.Second ||
+			schedule.LastReview != testCase.expected.LastReview ||
+			schedule.Reviews != testCase.expected.Reviews ||
+			schedule.Lapses != testCase.expected.Lapses {
+			t.Fatalf("%s: expected %+v, got %+v", testCase.name, testCase.expected, schedule)
+		}
+	}
+}
+
+func TestIntervalsGrow(t *testing.T) {
+	var (
+		now          = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		schedule     = PhraseSchedule{Difficulty: SRS_INITIAL_DIFFICULTY}
+		prevInterval time.Duration
+	)
+
+	for i := range 5 {
+		reschedule(&schedule, app.VerdictCorrect, now)
+
+		interval := schedule.Due.Sub(now)
+
+		if interval <= prevInterval {
+			t.Fatalf("interval of review %d (%s) isn't bigger than the previous one (%s)", i, interval, prevInterval)
+		}
+
+		prevInterval = interval
+		now = schedule.Due
+	}
+}
+
+func scheduledPhrases(count int, schedule func(i int) PhraseSchedule) []PhraseWithSchedule {
+	res := make([]PhraseWithSchedule, count)
+
+	for i, phrase := range testPhrases(count) {
+		res[i] = PhraseWithSchedule{Phrase: phrase, Schedule: schedule(i)}
+	}
+
+	return res
+}
+
+// Answers the task of the spaced repetition lesson rightly (see passLesson).
+func answerRightly(t *testing.T, task app.PhraseLearningTask, phrases []PhraseWithSchedule) {
+	passLesson(t, &singleTaskLesson{task}, phrasesOf(phrases), 1)
+}
+
+func phrasesOf(phrases []PhraseWithSchedule) []app.PhraseWithTranslation {
+	res := make([]app.PhraseWithTranslation, len(phrases))
+
+	for i := range phrases {
+		res[i] = phrases[i].Phrase
+	}
+
+	return res
+}
+
+// Returns the same task.
+type singleTaskLesson struct {
+	task app.PhraseLearningTask
+}
+
+func (l *singleTaskLesson) Next(context.Context) (app.PhraseLearningTask, error) {
+	return l.task, nil
+}
+
+func TestDuePhrasesFirst(t *testing.T) {
+	now := time.Now()
+
+	phrases := scheduledPhrases(5, func(i int) PhraseSchedule {
+		switch i {
+		case 0:
+			return PhraseSchedule{Stability: 1, Reviews: 2, Due: now.Add(-time.Hour * 24)}
+		case 1:
+			return PhraseSchedule{Stability: 1, Reviews: 2, Due: now.Add(-time.Hour * 48)}
+		case 2:
+			return PhraseSchedule{Stability: 1, Reviews: 2, Due: now.Add(time.Hour * 24)}
+		}
+
+		return PhraseSchedule{}
+	})
+
+	lesson, err := NewSpacedRepetition(phrases, WithSeed(1))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//The earliest due phrase is the first, new phrases are after due ones,
+	//the phrase which isn't due yet isn't shown.
+	for _, expected := range []string{"phrase 1", "phrase 0", "phrase 3", "phrase 4"} {
+		task, err := lesson.Next(t.Context())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if task.Phrase() != expected {
+			t.Fatalf("%q expected, got %q", expected, task.Phrase())
+		}
+
+		if _, manual := task.(app.TranslateManually); manual != (expected == "phrase 0" || expected == "phrase 1") {
+			t.Fatal("manual translation is expected for phrases with two reviews only:", expected)
+		}
+
+		answerRightly(t, task, phrases)
+	}
+
+	_, err = lesson.Next(t.Context())
+
+	if !errors.Is(err, app.ErrNoPhrasesToRepeat) {
+		t.Fatal("ErrNoPhrasesToRepeat expected, got:", err)
+	}
+
+	progress := lesson.GetProgress()
+
+	if progress[2].Schedule != phrases[2].Schedule || progress[0].Schedule.Reviews != 3 || progress[3].Schedule.Reviews != 1 {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+}
+
+func TestNewPhrasesAreLimited(t *testing.T) {
+	phrases := scheduledPhrases(SRS_NEW_PHRASES_PER_LESSON+5, func(int) PhraseSchedule { return PhraseSchedule{} })
+
+	lesson, err := NewSpacedRepetition(phrases, WithSeed(1))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+
+	for {
+		task, err := lesson.Next(t.Context())
+
+		if errors.Is(err, app.ErrNoPhrasesToRepeat) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seen[task.Phrase()] = true
+
+		answerRightly(t, task, phrases)
+	}
+
+	if len(seen) != SRS_NEW_PHRASES_PER_LESSON {
+		t.Fatal(fmt.Sprintf("%d new phrases expected, got", SRS_NEW_PHRASES_PER_LESSON), len(seen))
+	}
+}
+
+func TestNothingIsDue(t *testing.T) {
+	phrases := scheduledPhrases(3, func(int) PhraseSchedule {
+		return PhraseSchedule{Stability: 1, Reviews: 1, Due: time.Now().Add(time.Hour)}
+	})
+
+	lesson, err := NewSpacedRepetition(phrases)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = lesson.Next(t.Context())
+
+	if !errors.Is(err, app.ErrNoPhrasesToRepeat) {
+		t.Fatal("ErrNoPhrasesToRepeat expected, got:", err)
+	}
+}
@@ -0,0 +1,167 @@
+package advanced
+
+// Contains the logick of prioritizing tasks for their right order in lesson
+// and more productive learning. Returned weight is an unnormalized probability
+// of choice of the task by Lesson.Next().
+type WeightingStrategy interface {
+	WeightOfTask(learningStatistics *PhraseLearningStatistics, kindOfTask KindOfTask, taskInverted bool, spellingOnly bool) float64
+}
+
+// The implementation of WeightingStrategy which leads each phrase through stages:
+//   - choice of the right option (translation from foreign language);
+//   - mixed choice of the right option (to foreign and to known language);
+//   - choice of the right option (translation to foreign language);
+//   - manual translation (with choice tasks while share of right choices is low);
+//   - learned phrase (rarely repeated).
+//
+// In spelling lessons phrases which are often misspelled are more prioritized.
+type StagedWeighting struct {
+	//Counts of right choices of options after which the phrase moves to the next stage.
+	MixedChoiceFrom    uint32
+	InvertedChoiceFrom uint32
+	ManualFrom         uint32
+
+	//The phrase is still repeated by choice tasks while share of right choices is lower.
+	MinShareOfRightChoices float64
+
+	//The phrase is considered learned after more than LearnedAfterManualTasks manual translations
+	//with share of right answers bigger than MinShareOfRightManualAnswers.
+	LearnedAfterManualTasks      uint32
+	MinShareOfRightManualAnswers float64
+
+	//Weight of all the tasks of a learned phrase.
+	//Don't set it to zero! It will cause repetition of one phrase
+	//again and again when all the phrases are learned.
+	LearnedPhraseWeight float64
+}
+
+var _ WeightingStrategy = (*StagedWeighting)(nil)
+
+// Returns the strategy used by Lesson by default.
+func DefaultWeighting() *StagedWeighting {
+	return &StagedWeighting{
+		MixedChoiceFrom:              3,
+		InvertedChoiceFrom:           7,
+		ManualFrom:                   10,
+		MinShareOfRightChoices:       0.7,
+		LearnedAfterManualTasks:      10,
+		MinShareOfRightManualAnswers: 0.9,
+		LearnedPhraseWeight:          0.1,
+	}
+}
+
+// Returns the strategy with a gentler ramp for advanced learners:
+// manual translation begins earlier and phrases are considered learned faster.
+func AdvancedLearnerWeighting() *StagedWeighting {
+	return &StagedWeighting{
+		MixedChoiceFrom:              1,
+		InvertedChoiceFrom:           2,
+		ManualFrom:                   4,
+		MinShareOfRightChoices:       0.6,
+		LearnedAfterManualTasks:      5,
+		MinShareOfRightManualAnswers: 0.85,
+		LearnedPhraseWeight:          0.1,
+	}
+}
+
+func (w *StagedWeighting) WeightOfTask(learningStatistics *PhraseLearningStatistics, kindOfTask KindOfTask, taskInverted bool, spellingOnly bool) float64 {
+	if spellingOnly {
+		if kindOfTask == KindOfTaskTranslateManually && taskInverted {
+			return w.spellingWeight(learningStatistics)
+		}
+
+		return 0
+	}
+
+	OneOptionChoiceTasksPassed := learningStatistics.CountGuessedOOS +
+		learningStatistics.CountFailedOOS +
+		learningStatistics.CountGuessedOOSInverted +
+		learningStatistics.CountFailedOOSInverted
+
+	OneOptionChoiceTasksPassedSuccessfully := learningStatistics.CountGuessedOOS +
+		learningStatistics.CountGuessedOOSInverted
+
+	//First tasks are always translation from foreigh language
+	//to known one by selecting the right card.
+	if OneOptionChoiceTasksPassedSuccessfully < w.MixedChoiceFrom {
+		if kindOfTask == KindOfTaskChooseOneOption && !taskInverted {
+			return 1
+		}
+
+		return 0
+	}
+
+	//Than mixed mode of cards: to foregn and to known language.
+	if OneOptionChoiceTasksPassedSuccessfully < w.InvertedChoiceFrom {
+		if kindOfTask == KindOfTaskChooseOneOption {
+			//2 tasks are available for 1 phrase.
+			//Weight 1.0 will cause that this phrase will become more prioritized
+			//than other (sum of all tasks' weights for one phrase should be always 1.0).
+			return 0.5
+		}
+
+		return 0
+	}
+
+	//Further tasks by concrete phrase always will be translation
+	//from known language to foreign by cards.
+	if OneOptionChoiceTasksPassedSuccessfully < w.ManualFrom {
+		if kindOfTask == KindOfTaskChooseOneOption && taskInverted {
+			return 1
+		}
+
+		return 0
+	}
+
+	if float64(OneOptionChoiceTasksPassedSuccessfully)/float64(OneOptionChoiceTasksPassed) < w.MinShareOfRightChoices {
+		if kindOfTask == KindOfTaskChooseOneOption || (kindOfTask == KindOfTaskTranslateManually && !taskInverted) {
+			//3 tasks are available for 1 phrase.
+			//Weight 1.0 will cause that this phrase will become more prioritized
+			//than other (sum of all tasks' weights for one phrase should be always 1.0).
+			return float64(1) / float64(3)
+		}
+
+		return 0
+	}
+
+	//Translations with a few typos aren't considered as failures.
+	TranslateManuallyTasksPassed := learningStatistics.CountAnsweredTM +
+		learningStatistics.CountFailedTM +
+		learningStatistics.CountAlmostTM +
+		learningStatistics.CountAnsweredTMInverted +
+		learningStatistics.CountFailedTMInverted +
+		learningStatistics.CountAlmostTMInverted
+
+	TranslateManuallyTasksPassedSuccessfully := learningStatistics.CountAnsweredTM +
+		learningStatistics.CountAlmostTM +
+		learningStatistics.CountAnsweredTMInverted +
+		learningStatistics.CountAlmostTMInverted
+
+	//When the phrase is complitely learned, we need to
+	//make it less prioritized (to improve learning of other).
+	if TranslateManuallyTasksPassed > w.LearnedAfterManualTasks &&
+		float64(TranslateManuallyTasksPassedSuccessfully)/float64(TranslateManuallyTasksPassed) > w.MinShareOfRightManualAnswers {
+		return w.LearnedPhraseWeight
+	}
+
+	//All further learning (before the phrase is learned)
+	//will contain only manual translation.
+	if kindOfTask == KindOfTaskTranslateManually {
+		return 0.5
+	}
+
+	return 0
+}
+
+// Returns the estimated probability of misspelling of the phrase: 0.5 for a new phrase,
+// up to 1 for a phrase which is misspelled again and again. Translations with a few typos
+// are half-misspellings. The weight is never less than LearnedPhraseWeight.
+func (w *StagedWeighting) spellingWeight(learningStatistics *PhraseLearningStatistics) float64 {
+	misspellings := float64(learningStatistics.CountFailedTMInverted) + float64(learningStatistics.CountAlmostTMInverted)/2
+
+	answers := float64(learningStatistics.CountAnsweredTMInverted) +
+		float64(learningStatistics.CountFailedTMInverted) +
+		float64(learningStatistics.CountAlmostTMInverted)
+
+	return max((misspellings+1)/(answers+2), w.LearnedPhraseWeight)
+}
@@ -0,0 +1,72 @@
+package advanced
+
+import (
+	"math"
+	"testing"
+)
+
+// Weights of tasks of a phrase: choice, inverted choice, manual translation, inverted manual translation.
+type taskWeights [4]float64
+
+func weightsOfTasks(weighting WeightingStrategy, stats PhraseLearningStatistics) taskWeights {
+	return taskWeights{
+		weighting.WeightOfTask(&stats, KindOfTaskChooseOneOption, false, false),
+		weighting.WeightOfTask(&stats, KindOfTaskChooseOneOption, true, false),
+		weighting.WeightOfTask(&stats, KindOfTaskTranslateManually, false, false),
+		weighting.WeightOfTask(&stats, KindOfTaskTranslateManually, true, false),
+	}
+}
+
+func TestStagedWeighting(t *testing.T) {
+	var (
+		choice          = taskWeights{1, 0, 0, 0}
+		mixedChoice     = taskWeights{0.5, 0.5, 0, 0}
+		invertedChoice  = taskWeights{0, 1, 0, 0}
+		manual          = taskWeights{0, 0, 0.5, 0.5}
+		choiceAndManual = taskWeights{1.0 / 3, 1.0 / 3, 1.0 / 3, 0}
+		learned         = taskWeights{0.1, 0.1, 0.1, 0.1}
+	)
+
+	for _, testCase := range []struct {
+		name      string
+		weighting WeightingStrategy
+		stats     PhraseLearningStatistics
+		expected  taskWeights
+	}{
+		//The default strategy is the same as the hardcoded one was: stages begin
+		//after 3, 7 and 10 right choices, 70% of right choices and more than 10 manual
+		//translations with more than 90% of right ones are needed.
+		{"new", DefaultWeighting(), PhraseLearningStatistics{}, choice},
+		{"before mixed choice", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 2, CountFailedOOS: 5}, choice},
+		{"mixed choice", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 3}, mixedChoice},
+		{"before inverted choice", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 3, CountGuessedOOSInverted: 3}, mixedChoice},
+		{"inverted choice", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 4, CountGuessedOOSInverted: 3}, invertedChoice},
+		{"before manual", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 9}, invertedChoice},
+		{"manual", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10}, manual},
+		{"few right choices", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10, CountFailedOOS: 5}, choiceAndManual},
+		{"enough right choices", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 14, CountFailedOOSInverted: 6}, manual},
+		{"before learned", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10, CountAnsweredTM: 10}, manual},
+		{"learned", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10, CountAnsweredTM: 11}, learned},
+		{"learned with failures", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10, CountAnsweredTM: 10, CountFailedTMInverted: 1}, learned},
+		{"too many failures", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10, CountAnsweredTM: 9, CountFailedTM: 2}, manual},
+		{"typos aren't failures", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 10, CountAnsweredTM: 5, CountAlmostTMInverted: 6}, learned},
+
+		//The strategy for advanced learners passes the same stages faster.
+		{"advanced: new", AdvancedLearnerWeighting(), PhraseLearningStatistics{}, choice},
+		{"advanced: mixed choice", AdvancedLearnerWeighting(), PhraseLearningStatistics{CountGuessedOOS: 1}, mixedChoice},
+		{"advanced: inverted choice", AdvancedLearnerWeighting(), PhraseLearningStatistics{CountGuessedOOS: 2}, invertedChoice},
+		{"advanced: manual", AdvancedLearnerWeighting(), PhraseLearningStatistics{CountGuessedOOS: 4}, manual},
+		{"advanced: few right choices", AdvancedLearnerWeighting(), PhraseLearningStatistics{CountGuessedOOS: 4, CountFailedOOS: 3}, choiceAndManual},
+		{"advanced: enough right choices", AdvancedLearnerWeighting(), PhraseLearningStatistics{CountGuessedOOS: 6, CountFailedOOS: 4}, manual},
+		{"advanced: learned", AdvancedLearnerWeighting(), PhraseLearningStatistics{CountGuessedOOS: 4, CountAnsweredTM: 6}, learned},
+		{"default: not learned", DefaultWeighting(), PhraseLearningStatistics{CountGuessedOOS: 4, CountAnsweredTM: 6}, mixedChoice},
+	} {
+		weights := weightsOfTasks(testCase.weighting, testCase.stats)
+
+		for i := range weights {
+			if math.Abs(weights[i]-testCase.expected[i]) > 1e-9 {
+				t.Fatalf("%s: expected weights %v, got %v", testCase.name, testCase.expected, weights)
+			}
+		}
+	}
+}
@@ -6,4 +6,8 @@ var (
 	ErrNotEnoughPhrasesInLesson = errors.New("not enough phrases in lesson")
 
 	ErrTaskAlreadyTaken = errors.New("task has already been taken")
+
+	ErrNoPhrasesToRepeat = errors.New("no phrases to repeat now")
+
+	ErrNoTasksAvailable = errors.New("no tasks are available in lesson")
 )
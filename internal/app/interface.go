@@ -7,6 +7,7 @@ type LessonMode byte
 const (
 	LessonModeLern LessonMode = iota
 	LessonModeLeanSpellingOnly
+	LessonModeSpacedRepetition
 )
 
 type Lesson interface {
@@ -25,8 +26,25 @@ type ChooseRightOption interface {
 	GetRightAnswer(context.Context) (int, error)
 }
 
+// Result of check of a manually typed translation.
+type Verdict byte
+
+const (
+	VerdictWrong Verdict = iota
+
+	//The translation contains a few typos.
+	VerdictAlmostCorrect
+
+	VerdictCorrect
+)
+
 type TranslateManually interface {
 	PhraseLearningTask
-	Right(context.Context, string) (bool, error)
+	Right(context.Context, string) (Verdict, error)
 	GetRightAnswer(context.Context) (string, error)
+
+	//Returns the right translation after the task was answered (empty before it).
+	//Unlike GetRightAnswer, it isn't considered as a request of the right answer,
+	//so it is used to show typos of almost correct translations.
+	RightTranslation() string
 }
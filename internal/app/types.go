@@ -1,9 +1,85 @@
 package app
 
+import (
+	"strings"
+	"time"
+)
+
 type PhraseWithTranslation struct {
 	Phrase, Translation string
+
+	//Parts of Phrase and Translation which are accepted as right answers
+	//by themselves (for example, synonyms). Can be empty.
+	PhraseVariants, TranslationVariants []string
+
+	//Optional category of the phrase (for example, part of speech).
+	//Phrases with the same tag are more plausible wrong options of each other.
+	Tag string
+
+	//Number of the row among rows of the lesson with the same Phrase (0 for the first one).
+	Occurrence uint32
+}
+
+// Stable identity of a row of a lesson: duplicate phrases have different keys.
+type PhraseKey struct {
+	Phrase     string
+	Occurrence uint32
+}
+
+func (pwt *PhraseWithTranslation) Key() PhraseKey {
+	return PhraseKey{Phrase: pwt.Phrase, Occurrence: pwt.Occurrence}
 }
 
 func (pwt *PhraseWithTranslation) Invert() {
 	pwt.Phrase, pwt.Translation = pwt.Translation, pwt.Phrase
+	pwt.PhraseVariants, pwt.TranslationVariants = pwt.TranslationVariants, pwt.PhraseVariants
+}
+
+// Returns all the translations which are accepted as right:
+// the whole Translation and each of TranslationVariants.
+func (pwt *PhraseWithTranslation) AcceptedTranslations() []string {
+	res := make([]string, 0, len(pwt.TranslationVariants)+1)
+
+	res = append(res, pwt.Translation)
+
+	return append(res, pwt.TranslationVariants...)
+}
+
+// Splits text by any of separators (each rune of the string is a separator).
+// Returns nil if text contains less than two non-empty parts.
+func SplitVariants(text, separators string) []string {
+	if separators == "" {
+		return nil
+	}
+
+	parts := strings.FieldsFunc(
+		text,
+		func(r rune) bool {
+			return strings.ContainsRune(separators, r)
+		},
+	)
+
+	res := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+
+		if part != "" {
+			res = append(res, part)
+		}
+	}
+
+	if len(res) < 2 {
+		return nil
+	}
+
+	return res
+}
+
+// The user chose the option of ConfusedWith phrase instead of the right one
+// in tasks of Phrase (both are phrases of the first column).
+type Confusion struct {
+	Phrase, ConfusedWith string
+	Count                uint32
+	LastTimeUTC          time.Time
 }
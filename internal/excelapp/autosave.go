@@ -0,0 +1,180 @@
+package excelapp
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+	"vocabulary/internal/storage"
+)
+
+// Stores changes of lesson progress in background. Changes are gathered
+// during the delay (AUTOSAVE_DELAY usually) since the first of them and stored by one transaction,
+// so answers don't wait for the storage and the storage isn't written too often.
+type progressAutosaver struct {
+	storage       *storage.File
+	excelFilePath string
+	sheet         string
+	delay         time.Duration
+
+	//Changes are stored to the knowledge shared by all the lessons too.
+	sharedKnowledge bool
+
+	changesLocker sync.Mutex
+
+	//The last statistics of phrases (see begin), changes of the knowledge are differences with them.
+	reported map[app.PhraseKey]advanced.PhraseLearningStatistics
+
+	changed          map[app.PhraseKey]advanced.PhraseLearningStatistics
+	changedKnowledge map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics
+	timer            *time.Timer
+	closed           bool
+
+	//Protects from parallel saving by timer and by Close() call.
+	savingLocker sync.Mutex
+}
+
+func newProgressAutosaver(storage *storage.File, excelFilePath, sheet string, delay time.Duration, sharedKnowledge bool) *progressAutosaver {
+	return &progressAutosaver{
+		storage:          storage,
+		excelFilePath:    excelFilePath,
+		sheet:            sheet,
+		delay:            delay,
+		sharedKnowledge:  sharedKnowledge,
+		reported:         map[app.PhraseKey]advanced.PhraseLearningStatistics{},
+		changed:          map[app.PhraseKey]advanced.PhraseLearningStatistics{},
+		changedKnowledge: map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics{},
+	}
+}
+
+// Sets statistics of phrases at the beginning of the lesson (including ones taken from the shared knowledge).
+// Should be called before the first answer.
+func (a *progressAutosaver) begin(progress []advanced.PhraseWithLearningStatistics) {
+	a.changesLocker.Lock()
+	defer a.changesLocker.Unlock()
+
+	for _, phraseWithStats := range progress {
+		a.reported[phraseWithStats.Phrase.Key()] = phraseWithStats.LearningStatistics
+	}
+}
+
+// Implements advanced.ProgressListener. Goroutine-safe.
+func (a *progressAutosaver) PhraseChanged(phraseWithStats advanced.PhraseWithLearningStatistics) {
+	a.changesLocker.Lock()
+	defer a.changesLocker.Unlock()
+
+	if a.closed {
+		return
+	}
+
+	key := phraseWithStats.Phrase.Key()
+
+	a.changed[key] = phraseWithStats.LearningStatistics
+
+	if a.sharedKnowledge {
+		var (
+			reported     = a.reported[key]
+			knowledgeKey = advanced.NewKnowledgeKey(&phraseWithStats.Phrase)
+			changed      = a.changedKnowledge[knowledgeKey]
+		)
+
+		//Counters are never decreased during a lesson.
+		answers := phraseWithStats.LearningStatistics.Combine(&reported, func(x, y uint32) uint32 { return x - y })
+
+		a.changedKnowledge[knowledgeKey] = changed.Combine(&answers, func(x, y uint32) uint32 { return x + y })
+	}
+
+	a.reported[key] = phraseWithStats.LearningStatistics
+
+	a.scheduleSaving()
+}
+
+func (a *progressAutosaver) save() {
+	a.savingLocker.Lock()
+	defer a.savingLocker.Unlock()
+
+	a.changesLocker.Lock()
+
+	toStore := a.changed
+	knowledgeToStore := a.changedKnowledge
+
+	a.changed = map[app.PhraseKey]advanced.PhraseLearningStatistics{}
+	a.changedKnowledge = map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics{}
+	a.timer = nil
+
+	a.changesLocker.Unlock()
+
+	if len(toStore) > 0 {
+		err := a.storage.UpsertLessonProgress(context.Background(), a.excelFilePath, a.sheet, toStore)
+
+		if err != nil {
+			log.Printf("autosaving of progress of %q, %q: %v", a.excelFilePath, a.sheet, err)
+
+			a.requeue(toStore)
+		}
+	}
+
+	if len(knowledgeToStore) > 0 {
+		err := a.storage.UpsertPhraseKnowledge(context.Background(), knowledgeToStore)
+
+		if err != nil {
+			log.Printf("autosaving of shared knowledge of phrases of %q, %q: %v", a.excelFilePath, a.sheet, err)
+
+			a.requeueKnowledge(knowledgeToStore)
+		}
+	}
+}
+
+// Returns changes which weren't stored to be stored by the next saving
+// (newer statistics of the same phrases gathered meanwhile are kept).
+func (a *progressAutosaver) requeue(notStored map[app.PhraseKey]advanced.PhraseLearningStatistics) {
+	a.changesLocker.Lock()
+	defer a.changesLocker.Unlock()
+
+	for key, stats := range notStored {
+		if _, found := a.changed[key]; !found {
+			a.changed[key] = stats
+		}
+	}
+
+	a.scheduleSaving()
+}
+
+// Returns answers which weren't added to the shared knowledge to be added by the next saving.
+// Knowledge is changed by answers only, so they would be lost otherwise.
+func (a *progressAutosaver) requeueKnowledge(notStored map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics) {
+	a.changesLocker.Lock()
+	defer a.changesLocker.Unlock()
+
+	for key, answers := range notStored {
+		changed := a.changedKnowledge[key]
+
+		a.changedKnowledge[key] = changed.Combine(&answers, func(x, y uint32) uint32 { return x + y })
+	}
+
+	a.scheduleSaving()
+}
+
+// Starts the timer of saving if it isn't started. Should be called with changesLocker locked.
+func (a *progressAutosaver) scheduleSaving() {
+	if a.timer == nil && !a.closed {
+		a.timer = time.AfterFunc(a.delay, a.save)
+	}
+}
+
+// Stores all the gathered changes and stops saving of further ones.
+func (a *progressAutosaver) Close() {
+	a.changesLocker.Lock()
+
+	a.closed = true
+
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+
+	a.changesLocker.Unlock()
+
+	a.save()
+}
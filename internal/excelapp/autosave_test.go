@@ -0,0 +1,148 @@
+package excelapp
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+	"vocabulary/internal/storage"
+)
+
+func TestProgressAutosaver(t *testing.T) {
+	file, err := storage.Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	var (
+		car = app.PhraseWithTranslation{Phrase: "car", Translation: "машина"}
+		cat = app.PhraseWithTranslation{Phrase: "cat", Translation: "кошка"}
+		dog = app.PhraseWithTranslation{Phrase: "dog", Translation: "собака"}
+	)
+
+	//Progress of phrases which weren't changed is kept.
+	err = file.SaveLessonProgress(
+		t.Context(),
+		"file.xlsx",
+		"sheet",
+		map[app.PhraseKey]advanced.PhraseLearningStatistics{
+			car.Key(): {CountGuessedOOS: 1},
+			dog.Key(): {CountFailedTM: 5},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	load := func() map[app.PhraseKey]advanced.PhraseLearningStatistics {
+		loaded, err := file.LoadLessonProgress(t.Context(), "file.xlsx", "sheet")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return loaded
+	}
+
+	//The delay is long, so the timer doesn't fire during the test, saving by it is called directly.
+	autosaver := newProgressAutosaver(file, "file.xlsx", "sheet", time.Hour, false)
+
+	//Changes are gathered during the delay, only the last statistics are stored.
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: car, LearningStatistics: advanced.PhraseLearningStatistics{CountGuessedOOS: 2}})
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: cat, LearningStatistics: advanced.PhraseLearningStatistics{CountFailedOOS: 1}})
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: car, LearningStatistics: advanced.PhraseLearningStatistics{CountGuessedOOS: 3}})
+
+	if loaded := load(); len(loaded) != 2 || loaded[car.Key()].CountGuessedOOS != 1 {
+		t.Fatal("changes shouldn't be stored before the delay:", loaded)
+	}
+
+	autosaver.timer.Stop()
+
+	autosaver.save()
+
+	expected := map[app.PhraseKey]advanced.PhraseLearningStatistics{
+		car.Key(): {CountGuessedOOS: 3},
+		cat.Key(): {CountFailedOOS: 1},
+		dog.Key(): {CountFailedTM: 5},
+	}
+
+	if loaded := load(); len(loaded) != len(expected) || loaded[car.Key()] != expected[car.Key()] ||
+		loaded[cat.Key()] != expected[cat.Key()] || loaded[dog.Key()] != expected[dog.Key()] {
+		t.Fatalf("expected %+v, got %+v", expected, loaded)
+	}
+
+	//The timer stores changes after the delay.
+	autosaver = newProgressAutosaver(file, "file.xlsx", "sheet", time.Millisecond, false)
+
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: cat, LearningStatistics: advanced.PhraseLearningStatistics{CountFailedOOS: 3}})
+
+	deadline := time.Now().Add(time.Second * 10)
+
+	for load()[cat.Key()].CountFailedOOS != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("changes weren't stored after the delay:", load())
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	autosaver.Close()
+
+	//Close stores changes without waiting for the delay, later changes are ignored.
+	autosaver = newProgressAutosaver(file, "file.xlsx", "sheet", time.Hour, false)
+
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: cat, LearningStatistics: advanced.PhraseLearningStatistics{CountFailedOOS: 4}})
+
+	autosaver.Close()
+
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: dog, LearningStatistics: advanced.PhraseLearningStatistics{CountFailedTM: 6}})
+
+	autosaver.Close()
+
+	if loaded := load(); loaded[cat.Key()].CountFailedOOS != 4 || loaded[dog.Key()].CountFailedTM != 5 {
+		t.Fatal("changes should be stored by Close only:", loaded)
+	}
+}
+
+func TestFailedAutosavingIsRequeued(t *testing.T) {
+	file, err := storage.Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Writing to the closed storage fails.
+	file.Close()
+
+	var (
+		car = app.PhraseWithTranslation{Phrase: "car", Translation: "машина"}
+		cat = app.PhraseWithTranslation{Phrase: "cat", Translation: "кошка"}
+	)
+
+	autosaver := newProgressAutosaver(file, "file.xlsx", "sheet", time.Hour, true)
+
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: car, LearningStatistics: advanced.PhraseLearningStatistics{CountGuessedOOS: 1}})
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: cat, LearningStatistics: advanced.PhraseLearningStatistics{CountGuessedOOS: 1}})
+
+	autosaver.timer.Stop()
+
+	autosaver.save()
+
+	//Answers which weren't added to the knowledge are added to later ones.
+	autosaver.PhraseChanged(advanced.PhraseWithLearningStatistics{Phrase: car, LearningStatistics: advanced.PhraseLearningStatistics{CountGuessedOOS: 2}})
+
+	autosaver.Close()
+
+	if knowledge := autosaver.changedKnowledge[advanced.NewKnowledgeKey(&car)]; knowledge.CountGuessedOOS != 2 {
+		t.Fatal("answers which weren't added to the shared knowledge should be kept:", knowledge)
+	}
+
+	if len(autosaver.changed) != 2 || autosaver.changed[car.Key()].CountGuessedOOS != 2 || autosaver.changed[cat.Key()].CountGuessedOOS != 1 {
+		t.Fatal("changes which weren't stored should be kept:", autosaver.changed)
+	}
+}
@@ -0,0 +1,27 @@
+package excelapp
+
+import "time"
+
+const (
+	TIME_TO_STORE_LESSONS_PROGRESS      = time.Hour * 24 * 30 * 3
+	MAX_LESSONS_COUNT_TO_STORE_PROGRESS = 5000
+	STORAGE_FILE_PATH                   = "./storage"
+
+	//Each rune is a separator of variants of translation (like "car; automobile").
+	DEFAULT_TRANSLATION_SEPARATORS = ";/"
+
+	//The delay between an answer and saving of progress changed by it.
+	AUTOSAVE_DELAY = time.Second * 2
+)
+
+// Values of "weighting" flag.
+const (
+	WEIGHTING_DEFAULT          = "default"
+	WEIGHTING_ADVANCED_LEARNER = "advanced"
+)
+
+// Values of "distractors" flag.
+const (
+	DISTRACTORS_SIMILAR = "similar"
+	DISTRACTORS_RANDOM  = "random"
+)
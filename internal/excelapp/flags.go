@@ -0,0 +1,119 @@
+package excelapp
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"vocabulary/internal/app/advanced"
+)
+
+// Command line flags shared by all the front ends. Settings of comparison of answers
+// (languages, diacritics, ignorable words) are defaults: they are stored for a lesson
+// at its' first beginning, and stored settings are used for it later.
+type Flags struct {
+	StorageFilePath string
+
+	//Profile of the learner (the last selected one if empty).
+	Profile string
+
+	translationSeparators string
+	phraseLanguage        string
+	translationLanguage   string
+	ignoreDiacritics      bool
+	ignorableLeadingWords map[string][]string
+	seed                  int64
+	weightingName         string
+	optionsCount          int
+	distractorsName       string
+	sharedKnowledge       bool
+}
+
+// Defines the flags in fs. Values are available after fs.Parse().
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		ignorableLeadingWords: map[string][]string{},
+	}
+
+	fs.StringVar(&f.StorageFilePath, "storage", STORAGE_FILE_PATH, "custom storage file path")
+	fs.StringVar(&f.Profile, "profile", "", "profile of the learner, it is added if it is new (the last selected one by default)")
+	fs.StringVar(&f.translationSeparators, "separators", DEFAULT_TRANSLATION_SEPARATORS, "separators of accepted translations in the second column")
+	fs.StringVar(&f.phraseLanguage, "phrase-language", "", "language code of phrases (first column) for comparison of answers of new lessons")
+	fs.StringVar(&f.translationLanguage, "translation-language", "", "language code of translations (second column) for comparison of answers of new lessons")
+	fs.BoolVar(&f.ignoreDiacritics, "ignore-diacritics", false, "accept answers typed without diacritics in new lessons")
+	fs.Func(
+		"ignorable-words",
+		"words ignored at the beginning of answers of new lessons in a language, as language=word,word (for example, en=a,an,the,to; nothing is ignored if the list is empty). Can be repeated for several languages",
+		f.parseIgnorableLeadingWords,
+	)
+	fs.Int64Var(&f.seed, "seed", 0, "seed of tasks order to replay a logged session (0 means random)")
+	fs.StringVar(&f.weightingName, "weighting", WEIGHTING_DEFAULT, "tasks prioritizing strategy: "+WEIGHTING_DEFAULT+" or "+WEIGHTING_ADVANCED_LEARNER)
+	fs.IntVar(&f.optionsCount, "options", advanced.OPTIONS_COUNT, fmt.Sprintf("count of options in choice tasks (from %d to %d)", advanced.MIN_OPTIONS_COUNT, advanced.MAX_OPTIONS_COUNT))
+	fs.StringVar(&f.distractorsName, "distractors", DISTRACTORS_SIMILAR, "choice of wrong options: "+DISTRACTORS_SIMILAR+" or "+DISTRACTORS_RANDOM)
+
+	fs.BoolVar(&f.sharedKnowledge, "shared-knowledge", false, "share statistics of the same phrases between lessons")
+
+	return f
+}
+
+func (f *Flags) parseIgnorableLeadingWords(value string) error {
+	language, words, found := strings.Cut(value, "=")
+
+	language = strings.TrimSpace(language)
+
+	if !found || language == "" {
+		return fmt.Errorf("expected language=word,word, got %q", value)
+	}
+
+	//The language is added even with an empty list, so default words aren't used for it.
+	f.ignorableLeadingWords[language] = []string{}
+
+	for _, word := range strings.Split(words, ",") {
+		word = strings.TrimSpace(word)
+
+		if word != "" {
+			f.ignorableLeadingWords[language] = append(f.ignorableLeadingWords[language], word)
+		}
+	}
+
+	return nil
+}
+
+func (f *Flags) Settings() (Settings, error) {
+	var weighting advanced.WeightingStrategy
+
+	switch f.weightingName {
+	case WEIGHTING_DEFAULT:
+		weighting = advanced.DefaultWeighting()
+	case WEIGHTING_ADVANCED_LEARNER:
+		weighting = advanced.AdvancedLearnerWeighting()
+	default:
+		return Settings{}, fmt.Errorf("unknown weighting strategy %q", f.weightingName)
+	}
+
+	var distractorSelector advanced.DistractorSelector
+
+	switch f.distractorsName {
+	case DISTRACTORS_SIMILAR:
+		distractorSelector = advanced.DefaultSimilarDistractors()
+	case DISTRACTORS_RANDOM:
+		distractorSelector = advanced.RandomDistractors{}
+	default:
+		return Settings{}, fmt.Errorf("unknown distractors selection %q", f.distractorsName)
+	}
+
+	return Settings{
+		Weighting:             weighting,
+		DistractorSelector:    distractorSelector,
+		OptionsCount:          f.optionsCount,
+		TranslationSeparators: f.translationSeparators,
+		Normalization: advanced.Normalization{
+			IgnoreDiacritics:    f.ignoreDiacritics,
+			PhraseLanguage:      f.phraseLanguage,
+			TranslationLanguage: f.translationLanguage,
+
+			IgnorableLeadingWords: f.ignorableLeadingWords,
+		},
+		Seed:            f.seed,
+		SharedKnowledge: f.sharedKnowledge,
+	}, nil
+}
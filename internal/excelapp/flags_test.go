@@ -0,0 +1,42 @@
+package excelapp
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestIgnorableWordsFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	flags := RegisterFlags(fs)
+
+	err := fs.Parse([]string{"-ignorable-words", "en= the, to ,a", "-ignorable-words", "de="})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := flags.Settings()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{"en": {"the", "to", "a"}, "de": {}}
+
+	if !reflect.DeepEqual(settings.Normalization.IgnorableLeadingWords, expected) {
+		t.Fatal("unexpected ignorable words:", settings.Normalization.IgnorableLeadingWords)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+
+	fs.SetOutput(io.Discard)
+
+	RegisterFlags(fs)
+
+	if fs.Parse([]string{"-ignorable-words", "the,to"}) == nil {
+		t.Fatal("words without a language shouldn't be accepted")
+	}
+}
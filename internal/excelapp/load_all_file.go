@@ -0,0 +1,609 @@
+package excelapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+	"vocabulary/internal/storage"
+
+	"slices"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Settings of lessons which are the same for all the files and sheets.
+type Settings struct {
+	Weighting          advanced.WeightingStrategy
+	DistractorSelector advanced.DistractorSelector
+	OptionsCount       int
+
+	//Each rune is a separator of accepted variants of translation in the second column.
+	TranslationSeparators string
+
+	//Default for lessons without stored settings of comparison of answers.
+	//It is stored for a lesson at its' first beginning and isn't changed later.
+	Normalization advanced.Normalization
+
+	//Seed of all the lessons (random if zero).
+	Seed int64
+
+	//Statistics of phrases are shared by all the lessons: phrases of lessons without
+	//stored progress begin with statistics gathered in other lessons.
+	SharedKnowledge bool
+}
+
+// Loads all the phrases of an Excel sheet into a lesson and stores progress of lessons
+// in the storage. Used by all the front ends, so progress is the same in all of them.
+type LoadAllFile struct {
+	excelFile    *excelize.File
+	currentPath  string
+	sheets       []string
+	currentSheet string
+	mode         app.LessonMode
+	weighting    advanced.WeightingStrategy
+
+	distractorSelector advanced.DistractorSelector
+	optionsCount       int
+
+	//Each rune is a separator of accepted variants of translation in the second column.
+	translationSeparators string
+
+	normalization advanced.Normalization
+
+	//Seed of all the lessons (random if zero).
+	seed int64
+
+	sharedKnowledge bool
+
+	prevLesson         app.Lesson
+	prevLessonFilePath string
+	prevLessonSheet    string
+	autosaver          *progressAutosaver
+	storage            *storage.File
+}
+
+// Call Exit() to store progress of the last lesson.
+func New(storage *storage.File, settings Settings) *LoadAllFile {
+	return &LoadAllFile{
+		storage:               storage,
+		weighting:             settings.Weighting,
+		distractorSelector:    settings.DistractorSelector,
+		optionsCount:          settings.OptionsCount,
+		seed:                  settings.Seed,
+		translationSeparators: settings.TranslationSeparators,
+		normalization:         settings.Normalization,
+		sharedKnowledge:       settings.SharedKnowledge,
+	}
+}
+
+// Lesson whose session can be replayed by the same seed.
+type seededLesson interface {
+	Seed() (seed int64, known bool)
+}
+
+func (ai *LoadAllFile) SetLessonMode(mode app.LessonMode) {
+	ai.mode = mode
+}
+
+func (ai *LoadAllFile) GetLessonMode() app.LessonMode {
+	return ai.mode
+}
+
+func (ai *LoadAllFile) close() {
+	if ai.excelFile != nil {
+		ai.excelFile.Close()
+
+		ai.currentPath = ""
+		ai.sheets = []string{}
+
+		ai.excelFile = nil
+	}
+}
+
+// Stores progress of the last lesson and closes the file.
+func (ai *LoadAllFile) Exit() {
+	ai.saveProgressOfPrevLesson(nil, "", "")
+
+	ai.close()
+}
+
+func (ai *LoadAllFile) saveProgressOfPrevLesson(currentLesson app.Lesson, cueerntLessonFilePath, currentLessonSheet string) {
+	if ai.autosaver != nil {
+		ai.autosaver.Close()
+
+		ai.autosaver = nil
+	}
+
+	switch prevLesson := ai.prevLesson.(type) {
+	case *advanced.Lesson:
+		phrasesLearningStatistics := prevLesson.GetProgress()
+
+		toStore := make(map[app.PhraseKey]advanced.PhraseLearningStatistics, len(phrasesLearningStatistics))
+
+		for _, phraseWithStats := range phrasesLearningStatistics {
+			if !phraseWithStats.LearningStatistics.IsEmpty() {
+				toStore[phraseWithStats.Phrase.Key()] = phraseWithStats.LearningStatistics
+			}
+		}
+
+		ai.storage.SaveLessonProgress(context.Background(), ai.prevLessonFilePath, ai.prevLessonSheet, toStore)
+	case *advanced.SpacedRepetitionLesson:
+		phrasesWithSchedule := prevLesson.GetProgress()
+
+		toStore := make(map[app.PhraseKey]advanced.PhraseSchedule, len(phrasesWithSchedule))
+
+		for _, phraseWithSchedule := range phrasesWithSchedule {
+			if !phraseWithSchedule.Schedule.IsNew() {
+				toStore[phraseWithSchedule.Phrase.Key()] = phraseWithSchedule.Schedule
+			}
+		}
+
+		ai.storage.SaveLessonSchedule(context.Background(), ai.prevLessonFilePath, ai.prevLessonSheet, toStore)
+	}
+
+	ai.prevLesson = currentLesson
+	ai.prevLessonFilePath = cueerntLessonFilePath
+	ai.prevLessonSheet = currentLessonSheet
+}
+
+// Returns a function which stores each answer of the lesson.
+// It is called from the goroutine which checks the answer, so it doesn't block UI.
+// Lessons don't expect errors of the log, so they are only logged.
+func (ai *LoadAllFile) reviewsLog(lessonFilePath, lessonSheet string) advanced.ReviewsLog {
+	return func(review advanced.Review) {
+		err := ai.storage.AddReview(context.Background(), lessonFilePath, lessonSheet, review)
+
+		if err != nil {
+			log.Printf("logging of the answer to %q (%q, %q): %v", review.Phrase, lessonFilePath, lessonSheet, err)
+		}
+	}
+}
+
+// Returns a function which stores each confusion of the lesson. Like reviewsLog, errors are only logged.
+func (ai *LoadAllFile) confusionsLog(lessonFilePath, lessonSheet string) advanced.ConfusionsLog {
+	return func(confusion app.Confusion) {
+		err := ai.storage.AddConfusion(context.Background(), lessonFilePath, lessonSheet, confusion)
+
+		if err != nil {
+			log.Printf("logging of the confusion of %q with %q (%q, %q): %v", confusion.Phrase, confusion.ConfusedWith, lessonFilePath, lessonSheet, err)
+		}
+	}
+}
+
+func (ai *LoadAllFile) OpenLast() error {
+	path, sheet, mode, err := ai.storage.LoadLastOpen(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	ai.OpenFile(path)
+
+	ai.ChooseTopic(sheet)
+
+	ai.SetLessonMode(mode)
+
+	return nil
+}
+
+func (ai *LoadAllFile) OpenFile(path string) bool {
+	ai.close()
+
+	file, err := excelize.OpenFile(path)
+
+	if err != nil {
+		return false
+	}
+
+	ai.excelFile = file
+	ai.currentPath = path
+	ai.sheets = file.GetSheetList()
+
+	sheetFound := slices.Contains(ai.sheets, ai.currentSheet)
+
+	if !sheetFound {
+		ai.currentSheet = ai.sheets[0]
+	}
+
+	return true
+}
+
+func (ai *LoadAllFile) FilePath() string {
+	return ai.currentPath
+}
+
+func (ai *LoadAllFile) AvailableTopics() []string {
+	return ai.sheets
+}
+
+func (ai *LoadAllFile) Topic() string {
+	return ai.currentSheet
+}
+
+func (ai *LoadAllFile) ChooseTopic(s string) {
+	ai.currentSheet = s
+}
+
+func (ai *LoadAllFile) ProgressRecoveryIsAvailable() bool {
+	ai.saveProgressOfPrevLesson(nil, "", "")
+
+	if ai.mode != app.LessonModeLern && ai.mode != app.LessonModeLeanSpellingOnly {
+		return false
+	}
+
+	return ai.storage.SavedProgressAvailable(context.Background(), ai.currentPath, ai.currentSheet)
+}
+
+func (ai *LoadAllFile) CommonlyConfused() ([]app.Confusion, error) {
+	return ai.storage.LoadConfusions(context.Background(), ai.currentPath, ai.currentSheet)
+}
+
+func (ai *LoadAllFile) BeginLesson(recoverProgress bool) (app.Lesson, error) {
+	rows, err := ai.excelFile.Rows(ai.currentSheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ai.saveProgressOfPrevLesson(nil, "", "")
+
+	var (
+		phrases                  []advanced.PhraseWithLearningStatistics
+		storedStatisticsByPhrase map[app.PhraseKey]advanced.PhraseLearningStatistics
+		phrasesWithSchedule      []advanced.PhraseWithSchedule
+		storedScheduleByPhrase   map[app.PhraseKey]advanced.PhraseSchedule
+	)
+
+	switch ai.mode {
+	case app.LessonModeLern, app.LessonModeLeanSpellingOnly:
+		if recoverProgress {
+			storedStatisticsByPhrase, err = ai.storage.LoadLessonProgress(context.Background(), ai.currentPath, ai.currentSheet)
+
+			if err != nil {
+				return nil, err
+			}
+
+			phrases = make([]advanced.PhraseWithLearningStatistics, 0, len(storedStatisticsByPhrase))
+		} else {
+			phrases = []advanced.PhraseWithLearningStatistics{}
+		}
+	case app.LessonModeSpacedRepetition:
+		//The schedule is always recovered: it is useless without history of answers.
+		storedScheduleByPhrase, err = ai.storage.LoadLessonSchedule(context.Background(), ai.currentPath, ai.currentSheet)
+
+		if err != nil && !errors.Is(err, storage.ErrWasNotSaved) {
+			return nil, err
+		}
+
+		phrasesWithSchedule = make([]advanced.PhraseWithSchedule, 0, len(storedScheduleByPhrase))
+	}
+
+	//Count of rows with each phrase (duplicate phrases have different keys in the storage).
+	occurrences := map[string]uint32{}
+
+	for rows.Next() {
+		cols, err := rows.Columns()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cols) < 2 {
+			continue
+		}
+
+		phrase := cols[0]
+		translation := cols[1]
+
+		phraseWithTranslation := app.PhraseWithTranslation{
+			Phrase:              phrase,
+			Translation:         translation,
+			TranslationVariants: app.SplitVariants(translation, ai.translationSeparators),
+			Occurrence:          occurrences[phrase],
+		}
+
+		occurrences[phrase]++
+
+		//The optional third column contains the tag of phrase (for example, part of speech).
+		if len(cols) > 2 {
+			phraseWithTranslation.Tag = strings.TrimSpace(cols[2])
+		}
+
+		switch ai.mode {
+		case app.LessonModeLern, app.LessonModeLeanSpellingOnly:
+			learningStatistics := advanced.PhraseLearningStatistics{}
+
+			if recoverProgress {
+				storedStatisticsForThisPhrase, found := storedStatisticsByPhrase[phraseWithTranslation.Key()]
+
+				if found {
+					learningStatistics = storedStatisticsForThisPhrase
+				}
+			}
+
+			phrases = append(
+				phrases,
+				advanced.PhraseWithLearningStatistics{
+					Phrase:             phraseWithTranslation,
+					LearningStatistics: learningStatistics,
+				},
+			)
+		case app.LessonModeSpacedRepetition:
+			phrasesWithSchedule = append(
+				phrasesWithSchedule,
+				advanced.PhraseWithSchedule{
+					Phrase:   phraseWithTranslation,
+					Schedule: storedScheduleByPhrase[phraseWithTranslation.Key()],
+				},
+			)
+		}
+
+	}
+
+	confusions, err := ai.storage.LoadConfusions(context.Background(), ai.currentPath, ai.currentSheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	normalization, normalizationStored, err := ai.lessonNormalization()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res  app.Lesson
+		opts = []advanced.Option{
+			advanced.WithWeightingStrategy(ai.weighting),
+			advanced.WithDistractorSelector(ai.distractorSelector),
+			advanced.WithOptionsCount(ai.optionsCount),
+			advanced.WithReviewsLog(ai.reviewsLog(ai.currentPath, ai.currentSheet)),
+			advanced.WithNormalization(normalization),
+			advanced.WithConfusions(confusions),
+			advanced.WithConfusionsLog(ai.confusionsLog(ai.currentPath, ai.currentSheet)),
+		}
+	)
+
+	if ai.seed != 0 {
+		opts = append(opts, advanced.WithSeed(ai.seed))
+	}
+
+	var autosaver *progressAutosaver
+
+	switch ai.mode {
+	case app.LessonModeLern, app.LessonModeLeanSpellingOnly:
+		autosaver = newProgressAutosaver(ai.storage, ai.currentPath, ai.currentSheet, AUTOSAVE_DELAY, ai.sharedKnowledge)
+
+		opts = append(opts, advanced.WithProgressListener(autosaver.PhraseChanged))
+
+		//Phrases of a lesson with stored progress which weren't answered yet begin from the first stage.
+		if ai.sharedKnowledge && !ai.storage.SavedProgressAvailable(context.Background(), ai.currentPath, ai.currentSheet) {
+			knowledgeKeys := make([]advanced.KnowledgeKey, len(phrases))
+
+			for i := range phrases {
+				knowledgeKeys[i] = advanced.NewKnowledgeKey(&phrases[i].Phrase)
+			}
+
+			knowledge, err := ai.storage.LoadPhraseKnowledge(context.Background(), knowledgeKeys)
+
+			if err != nil {
+				return nil, err
+			}
+
+			opts = append(opts, advanced.WithSharedKnowledge(knowledge))
+		}
+
+		lesson, err := advanced.NewWithProgress(phrases, ai.mode == app.LessonModeLeanSpellingOnly, opts...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		autosaver.begin(lesson.GetProgress())
+
+		res = lesson
+	case app.LessonModeSpacedRepetition:
+		res, err = advanced.NewSpacedRepetition(phrasesWithSchedule, opts...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		seed      int64
+		seedKnown bool
+	)
+
+	if seeded, ok := res.(seededLesson); ok {
+		seed, seedKnown = seeded.Seed()
+	}
+
+	if seedKnown {
+		session := storage.Session{
+			Mode:              ai.mode,
+			Seed:              seed,
+			ProgressRecovered: recoverProgress || ai.mode == app.LessonModeSpacedRepetition,
+			StateHash:         stateHash(res, confusions),
+		}
+
+		ai.storage.SaveSession(context.Background(), ai.currentPath, ai.currentSheet, session)
+	} else {
+		ai.storage.SaveLastOpen(context.Background(), ai.currentPath, ai.currentSheet, ai.mode)
+	}
+
+	if !normalizationStored {
+		err = ai.storage.SaveNormalization(context.Background(), ai.currentPath, ai.currentSheet, normalization)
+
+		if err != nil {
+			log.Printf("storing of settings of comparison of answers of %q, %q: %v", ai.currentPath, ai.currentSheet, err)
+		}
+	}
+
+	fingerprint, err := ai.fingerprint()
+
+	if err == nil {
+		ai.storage.SaveFingerprint(context.Background(), ai.currentPath, ai.currentSheet, fingerprint)
+	}
+
+	ai.saveProgressOfPrevLesson(res, ai.currentPath, ai.currentSheet)
+
+	ai.autosaver = autosaver
+
+	return res, nil
+}
+
+// Returns the fingerprint of phrases and translations of the current topic.
+func (ai *LoadAllFile) fingerprint() (storage.Fingerprint, error) {
+	rows, err := ai.excelFile.Rows(ai.currentSheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var fingerprintRows []string
+
+	for rows.Next() {
+		cols, err := rows.Columns()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cols) >= 2 {
+			fingerprintRows = append(fingerprintRows, cols[0]+"\t"+cols[1])
+		}
+	}
+
+	return storage.NewFingerprint(fingerprintRows), nil
+}
+
+// Returns settings of comparison of answers of the current lesson. The settings
+// of the application are the defaults only for lessons without stored ones.
+func (ai *LoadAllFile) lessonNormalization() (normalization advanced.Normalization, stored bool, err error) {
+	normalization, err = ai.storage.LoadNormalization(context.Background(), ai.currentPath, ai.currentSheet)
+
+	if errors.Is(err, storage.ErrWasNotSaved) {
+		return ai.normalization, false, nil
+	}
+
+	return normalization, err == nil, err
+}
+
+// Returns the hash of the state of the lesson before the first task: progress of its' phrases
+// and confusions between them. Sessions with the same seed, settings and hash have the same tasks
+// (tasks of a spaced repetition depend on the current time too).
+func stateHash(lesson app.Lesson, confusions []app.Confusion) string {
+	hash := sha256.New()
+
+	switch lesson := lesson.(type) {
+	case *advanced.Lesson:
+		for _, phrase := range lesson.GetProgress() {
+			fmt.Fprintf(hash, "%+v\n", phrase)
+		}
+	case *advanced.SpacedRepetitionLesson:
+		for _, phrase := range lesson.GetProgress() {
+			fmt.Fprintf(hash, "%+v\n", phrase)
+		}
+	}
+
+	for _, confusion := range confusions {
+		fmt.Fprintf(hash, "%+v\n", confusion)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Returns a lesson with progress whose content is similar to the current topic and whose
+// file or sheet doesn't exist anymore (probably it was moved or renamed).
+// It is found only if the current topic has no progress.
+func (ai *LoadAllFile) RelinkCandidate() (filePath, sheet string, found bool) {
+	if ai.excelFile == nil {
+		return "", "", false
+	}
+
+	fingerprint, err := ai.fingerprint()
+
+	if err != nil {
+		return "", "", false
+	}
+
+	candidates, err := ai.storage.FindRelinkCandidates(context.Background(), ai.currentPath, ai.currentSheet, fingerprint)
+
+	if err != nil {
+		return "", "", false
+	}
+
+	for _, candidate := range candidates {
+		if candidate.FilePath == ai.currentPath {
+			if !slices.Contains(ai.sheets, candidate.Sheet) {
+				return candidate.FilePath, candidate.Sheet, true
+			}
+
+			continue
+		}
+
+		_, err = os.Stat(candidate.FilePath)
+
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate.FilePath, candidate.Sheet, true
+		}
+	}
+
+	return "", "", false
+}
+
+// Moves all the stored data of the lesson to the current topic.
+func (ai *LoadAllFile) Relink(filePath, sheet string) error {
+	ai.saveProgressOfPrevLesson(nil, "", "")
+
+	return ai.storage.Relink(context.Background(), filePath, sheet, ai.currentPath, ai.currentSheet)
+}
+
+// Returns names of all the profiles of learners, the most recently selected first.
+func (ai *LoadAllFile) Profiles() []string {
+	profiles, err := ai.storage.Profiles(context.Background())
+
+	if err != nil {
+		return []string{ai.Profile()}
+	}
+
+	return profiles
+}
+
+func (ai *LoadAllFile) Profile() string {
+	return ai.storage.Profile()
+}
+
+// Stores progress of the previous lesson and selects the profile (it is added if it is new).
+// The last lesson opened in the profile is opened, if there is one.
+func (ai *LoadAllFile) SelectProfile(name string) error {
+	ai.saveProgressOfPrevLesson(nil, "", "")
+
+	err := ai.storage.SelectProfile(context.Background(), name)
+
+	if err != nil {
+		return err
+	}
+
+	err = ai.OpenLast()
+
+	if errors.Is(err, storage.ErrWasNotSaved) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,228 @@
+package excelapp
+
+import (
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+	"vocabulary/internal/storage"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Returns the count of answers to tasks of the phrase.
+func answersCount(stats *advanced.PhraseLearningStatistics) uint32 {
+	return stats.CountGuessedOOS + stats.CountFailedOOS + stats.CountAnsweredTM + stats.CountFailedTM +
+		stats.CountGuessedOOSInverted + stats.CountFailedOOSInverted + stats.CountAnsweredTMInverted +
+		stats.CountFailedTMInverted + stats.CountAlmostTM + stats.CountAlmostTMInverted
+}
+
+func lessonAnswersCount(lesson *advanced.Lesson) uint32 {
+	var res uint32
+
+	for _, phrase := range lesson.GetProgress() {
+		res += answersCount(&phrase.LearningStatistics)
+	}
+
+	return res
+}
+
+// Answers tasks of the lesson (rightly or not).
+func answerTasks(t *testing.T, lesson app.Lesson, count int) {
+	for range count {
+		task, err := lesson.Next(t.Context())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch task := task.(type) {
+		case app.ChooseRightOption:
+			_, err = task.Right(t.Context(), 0)
+		case app.TranslateManually:
+			_, err = task.Right(t.Context(), "answer")
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSharedKnowledgeOfTwoSheets(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		excelPath = filepath.Join(dir, "words.xlsx")
+		phrases   = [][]string{{"car", "машина"}, {"cat", "кошка"}}
+	)
+
+	excelFile := excelize.NewFile()
+
+	for _, sheet := range []string{"Unit 1", "Unit 2"} {
+		_, err := excelFile.NewSheet(sheet)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, phrase := range phrases {
+			err = excelFile.SetSheetRow(sheet, "A"+string(rune('1'+i)), &phrase)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	err := excelFile.SaveAs(excelPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := storage.Open(t.Context(), filepath.Join(dir, "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	ai := New(
+		file,
+		Settings{
+			Weighting:          advanced.DefaultWeighting(),
+			DistractorSelector: advanced.RandomDistractors{},
+			OptionsCount:       advanced.OPTIONS_COUNT,
+			Seed:               1,
+			SharedKnowledge:    true,
+		},
+	)
+
+	if !ai.OpenFile(excelPath) {
+		t.Fatal("the file wasn't opened")
+	}
+
+	beginLesson := func(sheet string, recoverProgress bool, expectedAnswers uint32) *advanced.Lesson {
+		ai.ChooseTopic(sheet)
+
+		lesson, err := ai.BeginLesson(recoverProgress)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if answers := lessonAnswersCount(lesson.(*advanced.Lesson)); answers != expectedAnswers {
+			t.Fatalf("%s: lesson should begin with %d answers, got %d", sheet, expectedAnswers, answers)
+		}
+
+		return lesson.(*advanced.Lesson)
+	}
+
+	answerTasks(t, beginLesson("Unit 2", false, 0), 2)
+
+	//The lesson without stored progress begins with answers given in the other sheet.
+	answerTasks(t, beginLesson("Unit 1", false, 2), 3)
+
+	//The lesson with stored progress begins with its' own answers.
+	answerTasks(t, beginLesson("Unit 2", true, 2), 1)
+
+	ai.Exit()
+
+	keys := make([]advanced.KnowledgeKey, len(phrases))
+
+	for i, phrase := range phrases {
+		keys[i] = advanced.NewKnowledgeKey(&app.PhraseWithTranslation{Phrase: phrase[0], Translation: phrase[1]})
+	}
+
+	knowledge, err := file.LoadPhraseKnowledge(t.Context(), keys)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var answers uint32
+
+	for _, stats := range knowledge {
+		answers += answersCount(&stats)
+	}
+
+	//Each answer is counted once.
+	if answers != 6 {
+		t.Fatal("6 answers are expected in the shared knowledge, got", answers, knowledge)
+	}
+}
+
+func TestNormalizationIsStoredPerLesson(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		excelPath = filepath.Join(dir, "words.xlsx")
+	)
+
+	excelFile := excelize.NewFile()
+
+	err := excelFile.SetSheetRow("Sheet1", "A1", &[]string{"école", "school"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = excelFile.SaveAs(excelPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := storage.Open(t.Context(), filepath.Join(dir, "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	beginLesson := func(normalization advanced.Normalization) {
+		ai := New(
+			file,
+			Settings{
+				Weighting:          advanced.DefaultWeighting(),
+				DistractorSelector: advanced.RandomDistractors{},
+				OptionsCount:       advanced.OPTIONS_COUNT,
+				Normalization:      normalization,
+			},
+		)
+
+		if !ai.OpenFile(excelPath) {
+			t.Fatal("the file wasn't opened")
+		}
+
+		ai.ChooseTopic("Sheet1")
+
+		_, err := ai.BeginLesson(false)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ai.Exit()
+	}
+
+	first := advanced.Normalization{IgnoreDiacritics: true, PhraseLanguage: "fr", TranslationLanguage: "en"}
+
+	beginLesson(first)
+
+	//Settings of the new lesson are the defaults, they are ignored when the lesson is begun again.
+	beginLesson(advanced.Normalization{TranslationLanguage: "de"})
+
+	stored, err := file.LoadNormalization(t.Context(), excelPath, "Sheet1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.IgnoreDiacritics != first.IgnoreDiacritics ||
+		stored.PhraseLanguage != first.PhraseLanguage ||
+		stored.TranslationLanguage != first.TranslationLanguage {
+		t.Fatalf("stored settings %+v differ from the first ones %+v", stored, first)
+	}
+}
@@ -1,16 +1,25 @@
 package random
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"math/bits"
 	"math/rand"
 )
 
+// Discrete random variable with values 0..n-1 and changeable weights (unnormalized probabilities).
+// Weights are stored in a binary indexed (Fenwick) tree, so both change of a weight
+// and getting of a value take O(log n) time.
 type DiscreteRandomVariable struct {
-	intervals                []float64
-	valuesWeights            []float64
-	weightsSum               float64
-	randSource               *rand.Rand
-	recalculateintervalsFrom int
+	//tree[i-1] is a sum of weights of values (i - lowest set bit of i)..i-1.
+	tree          []float64
+	valuesWeights []float64
+	randSource    *rand.Rand
+
+	//Each change of a weight adds rounding error to the tree,
+	//so it is periodically rebuilt from valuesWeights.
+	updatesSinceRebuild int
 }
 
 func NewDiscreteRandomVariable(randSource *rand.Rand, weights []float64) (*DiscreteRandomVariable, error) {
@@ -22,11 +31,28 @@ func NewDiscreteRandomVariable(randSource *rand.Rand, weights []float64) (*Discr
 		return nil, ErrEmptyWeightsSlice
 	}
 
-	res.SetWeights(weights)
+	err := res.SetWeights(weights)
+
+	if err != nil {
+		return nil, err
+	}
 
 	return res, nil
 }
 
+// Weights should be finite and not negative (zero is allowed).
+func validateWeight(weight float64) error {
+	if math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return ErrNotFiniteWeight
+	}
+
+	if weight < 0 {
+		return ErrNegativeWeight
+	}
+
+	return nil
+}
+
 func (rv *DiscreteRandomVariable) GetWeights() []float64 {
 	res := make([]float64, len(rv.valuesWeights))
 
@@ -39,109 +65,213 @@ func (rv *DiscreteRandomVariable) GetWeight(i int) float64 {
 	return rv.valuesWeights[i]
 }
 
+// Replaces all the weights. Keeps the previous weights if any of new ones is invalid.
 func (rv *DiscreteRandomVariable) SetWeights(weights []float64) error {
 	if len(weights) <= 0 {
 		return ErrEmptyWeightsSlice
 	}
 
+	for i, weight := range weights {
+		err := validateWeight(weight)
+
+		if err != nil {
+			return fmt.Errorf("weight %d: %w", i, err)
+		}
+	}
+
 	if len(rv.valuesWeights) >= len(weights) {
 		rv.valuesWeights = rv.valuesWeights[:len(weights)]
-		rv.intervals = rv.intervals[:len(weights)-1]
+		rv.tree = rv.tree[:len(weights)]
 	} else {
 		rv.valuesWeights = make([]float64, len(weights))
-		rv.intervals = make([]float64, len(weights)-1)
+		rv.tree = make([]float64, len(weights))
 	}
 
 	copy(rv.valuesWeights, weights)
 
-	rv.weightsSum = 0
-
-	for _, weight := range rv.valuesWeights {
-		rv.weightsSum += weight
-	}
-
-	rv.recalculateintervalsFrom = 0
+	rv.rebuildTree()
 
 	return nil
 }
 
-func (rv *DiscreteRandomVariable) recalculateIntervals(from int) {
-	prevSectionValue := float64(0)
+// Builds the tree from valuesWeights in O(n) time.
+func (rv *DiscreteRandomVariable) rebuildTree() {
+	copy(rv.tree, rv.valuesWeights)
+
+	for i := 1; i <= len(rv.tree); i++ {
+		parent := i + i&-i
 
-	if from > 0 {
-		prevSectionValue = rv.intervals[from-1]
+		if parent <= len(rv.tree) {
+			rv.tree[parent-1] += rv.tree[i-1]
+		}
 	}
 
-	for intervalIndex := from; intervalIndex < len(rv.intervals); intervalIndex++ {
-		currentSectionValue := prevSectionValue + rv.valuesWeights[intervalIndex]
+	rv.updatesSinceRebuild = 0
+}
 
-		rv.intervals[intervalIndex] = currentSectionValue
+// Changes the weight of value i. Keeps the previous weight if the new one is invalid.
+func (rv *DiscreteRandomVariable) SetWeight(i int, weight float64) error {
+	err := validateWeight(weight)
 
-		prevSectionValue = currentSectionValue
+	if err != nil {
+		return err
 	}
-}
 
-func (rv *DiscreteRandomVariable) SetWeight(i int, weight float64) {
 	oldWeight := rv.valuesWeights[i]
 
-	//To avoid extra recalculation of intervals.
+	//To avoid extra accumulation of rounding errors.
 	if weight == oldWeight {
-		return
+		return nil
 	}
 
-	rv.weightsSum -= oldWeight
+	rv.valuesWeights[i] = weight
 
-	rv.weightsSum += weight
+	rv.updatesSinceRebuild++
 
-	rv.valuesWeights[i] = weight
+	//Rebuilding after n updates keeps amortized time of update O(log n).
+	if rv.updatesSinceRebuild >= len(rv.valuesWeights) {
+		rv.rebuildTree()
 
-	if rv.recalculateintervalsFrom == -1 || i < rv.recalculateintervalsFrom {
-		rv.recalculateintervalsFrom = i
+		return nil
 	}
+
+	delta := weight - oldWeight
+
+	for treeIndex := i + 1; treeIndex <= len(rv.tree); treeIndex += treeIndex & -treeIndex {
+		rv.tree[treeIndex-1] += delta
+	}
+
+	return nil
+}
+
+// Returns the sum of weights of all the values.
+func (rv *DiscreteRandomVariable) weightsSum() float64 {
+	res := float64(0)
+
+	for treeIndex := len(rv.tree); treeIndex > 0; treeIndex -= treeIndex & -treeIndex {
+		res += rv.tree[treeIndex-1]
+	}
+
+	return res
 }
 
+// Returns a random value. If all the weights are zero, returns any value
+// (use TryGet() when it is possible).
 func (rv *DiscreteRandomVariable) Get() int {
-	if rv.recalculateintervalsFrom != -1 {
-		rv.recalculateIntervals(rv.recalculateintervalsFrom)
+	value, _ := rv.TryGet()
+
+	return value
+}
+
+// Returns a random value or ErrZeroTotalWeight if all the weights are zero.
+func (rv *DiscreteRandomVariable) TryGet() (int, error) {
+	weightsSum := rv.weightsSum()
 
-		rv.recalculateintervalsFrom = -1
+	if weightsSum <= 0 {
+		return 0, ErrZeroTotalWeight
 	}
 
-	//binary search
+	randomNumberToFindSection := float64(rv.randSource.Uint64()) * weightsSum / float64(math.MaxUint64)
 
-	var (
-		randomNumberToFindSection = float64(rv.randSource.Uint64()) * rv.weightsSum / float64(math.MaxUint64)
+	//Descent by the tree to the first value whose section contains the random number.
+	valueIndex := 0
 
-		left       = 0
-		right      = len(rv.valuesWeights)
-		valueIndex int
-	)
+	for step := 1 << (bits.Len(uint(len(rv.tree))) - 1); step > 0; step >>= 1 {
+		next := valueIndex + step
 
-	for {
-		valueIndex = (right + left) / 2
+		if next <= len(rv.tree) && rv.tree[next-1] <= randomNumberToFindSection {
+			valueIndex = next
 
-		if valueIndex > 0 {
-			sectionBegin := rv.intervals[valueIndex-1]
+			randomNumberToFindSection -= rv.tree[next-1]
+		}
+	}
 
-			if randomNumberToFindSection < sectionBegin {
-				right = valueIndex
+	valueIndex = rv.nearestValueWithWeight(valueIndex)
 
-				continue
-			}
+	//The sum of weights can be positive because of rounding errors only.
+	if rv.valuesWeights[valueIndex] <= 0 {
+		return 0, ErrZeroTotalWeight
+	}
+
+	return valueIndex, nil
+}
+
+// Rounding errors can lead the descent to a value with zero weight
+// (or out of range when the random number equals the sum of weights),
+// such values shouldn't be returned.
+func (rv *DiscreteRandomVariable) nearestValueWithWeight(i int) int {
+	i = min(i, len(rv.valuesWeights)-1)
+
+	for j := i; j >= 0; j-- {
+		if rv.valuesWeights[j] > 0 {
+			return j
+		}
+	}
+
+	for j := i + 1; j < len(rv.valuesWeights); j++ {
+		if rv.valuesWeights[j] > 0 {
+			return j
 		}
+	}
+
+	return i
+}
+
+// Returns k distinct values chosen according to their weights (sampling without replacement).
+// Values from exclude and values with zero weight are never returned. Takes O(k log n) time.
+func (rv *DiscreteRandomVariable) GetDistinct(k int, exclude ...int) ([]int, error) {
+	if k > len(rv.valuesWeights) {
+		return nil, ErrNotEnoughValues
+	}
 
-		if valueIndex < len(rv.intervals) {
-			sectionEnd := rv.intervals[valueIndex]
+	//Chosen and excluded values get zero weight until the end of sampling.
+	//A slice (not a map) keeps the order of restoring, so results are reproducible.
+	hidden := make([]valueWithWeight, 0, k+len(exclude))
 
-			if randomNumberToFindSection >= sectionEnd {
-				left = valueIndex
+	defer func() {
+		for i := len(hidden) - 1; i >= 0; i-- {
+			rv.SetWeight(hidden[i].Value, hidden[i].Weight)
+		}
+	}()
 
-				continue
+	hide := func(value int) {
+		for _, hiddenValue := range hidden {
+			if hiddenValue.Value == value {
+				return
 			}
 		}
 
-		break
+		hidden = append(hidden, valueWithWeight{Value: value, Weight: rv.valuesWeights[value]})
+
+		rv.SetWeight(value, 0)
+	}
+
+	for _, value := range exclude {
+		hide(value)
 	}
 
-	return valueIndex
+	res := make([]int, 0, k)
+
+	for len(res) < k {
+		value, err := rv.TryGet()
+
+		if errors.Is(err, ErrZeroTotalWeight) {
+			return nil, ErrNotEnoughValues
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, value)
+
+		hide(value)
+	}
+
+	return res, nil
+}
+
+type valueWithWeight struct {
+	Value  int
+	Weight float64
 }
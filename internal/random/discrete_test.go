@@ -1,8 +1,11 @@
 package random
 
 import (
+	"errors"
 	"log"
+	"math"
 	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -23,6 +26,75 @@ func TestEmptySlice(t *testing.T) {
 	}
 }
 
+func TestInvalidWeights(t *testing.T) {
+	randSource := rand.New(rand.NewSource(0))
+
+	_, err := NewDiscreteRandomVariable(randSource, []float64{1, -1})
+
+	if !errors.Is(err, ErrNegativeWeight) {
+		t.Fatal("ErrNegativeWeight should be returned, got:", err)
+	}
+
+	drv, err := NewDiscreteRandomVariable(randSource, []float64{1, 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, weight := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		err = drv.SetWeight(0, weight)
+
+		if !errors.Is(err, ErrNotFiniteWeight) {
+			t.Fatal("ErrNotFiniteWeight should be returned, got:", err)
+		}
+	}
+
+	err = drv.SetWeights([]float64{3, -3})
+
+	if !errors.Is(err, ErrNegativeWeight) {
+		t.Fatal("ErrNegativeWeight should be returned, got:", err)
+	}
+
+	if !slices.Equal(drv.GetWeights(), []float64{1, 2}) {
+		t.Fatal("weights shouldn't be changed by invalid ones:", drv.GetWeights())
+	}
+}
+
+func TestZeroTotalWeight(t *testing.T) {
+	drv, err := NewDiscreteRandomVariable(rand.New(rand.NewSource(0)), []float64{0.1, 0.2, 0.3})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Rounding errors of the tree shouldn't make the sum of zero weights positive.
+	for i := range 3 {
+		err = drv.SetWeight(i, 0)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = drv.TryGet()
+
+	if !errors.Is(err, ErrZeroTotalWeight) {
+		t.Fatal("ErrZeroTotalWeight should be returned, got:", err)
+	}
+
+	err = drv.SetWeight(1, 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	num, err := drv.TryGet()
+
+	if err != nil || num != 1 {
+		t.Fatal("the only value with positive weight should be returned, got:", num, err)
+	}
+}
+
 func testsameWeightWithparams(t *testing.T, numsCount, experimentsCount uint) {
 	counters := make([]uint, numsCount)
 	weights := make([]float64, numsCount)
@@ -109,3 +181,140 @@ func TestDifferentWeights(t *testing.T) {
 		}
 	}
 }
+
+func TestZeroWeightsAfterManyUpdates(t *testing.T) {
+	numsCount := 1000
+
+	randSource := rand.New(rand.NewSource(0))
+
+	weights := make([]float64, numsCount)
+
+	for i := range weights {
+		weights[i] = randSource.Float64()
+	}
+
+	drv, err := NewDiscreteRandomVariable(randSource, weights)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Changes of weights with rounding errors and periodical rebuilding of the tree.
+	for i := range 10 * numsCount {
+		drv.SetWeight(randSource.Intn(numsCount), randSource.Float64()/float64(i+1))
+	}
+
+	for i := range numsCount {
+		if i%2 == 0 {
+			drv.SetWeight(i, 0)
+		}
+	}
+
+	for range 100000 {
+		num := drv.Get()
+
+		if drv.GetWeight(num) == 0 {
+			t.Fatal("value with zero weight was returned:", num)
+		}
+	}
+}
+
+const BENCHMARK_VALUES_COUNT = 200000
+
+func newBenchmarkVariable(b *testing.B) (*DiscreteRandomVariable, *rand.Rand) {
+	randSource := rand.New(rand.NewSource(0))
+
+	weights := make([]float64, BENCHMARK_VALUES_COUNT)
+
+	for i := range weights {
+		weights[i] = randSource.Float64()
+	}
+
+	drv, err := NewDiscreteRandomVariable(randSource, weights)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return drv, randSource
+}
+
+func BenchmarkSetWeight(b *testing.B) {
+	drv, randSource := newBenchmarkVariable(b)
+
+	for b.Loop() {
+		drv.SetWeight(randSource.Intn(BENCHMARK_VALUES_COUNT), randSource.Float64())
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	drv, _ := newBenchmarkVariable(b)
+
+	for b.Loop() {
+		drv.Get()
+	}
+}
+
+// Changes of weights made by a lesson after an answer: 4 tasks of the phrase
+// and the tasks of the last phrases which shouldn't be repeated, then choice of the next task.
+func BenchmarkAnswer(b *testing.B) {
+	drv, randSource := newBenchmarkVariable(b)
+
+	for b.Loop() {
+		for range 12 {
+			drv.SetWeight(randSource.Intn(BENCHMARK_VALUES_COUNT), randSource.Float64())
+		}
+
+		drv.Get()
+	}
+}
+
+func TestGetDistinct(t *testing.T) {
+	weights := []float64{1, 0, 2, 1, 1, 3}
+
+	drv, err := NewDiscreteRandomVariable(rand.New(rand.NewSource(0)), weights)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 1000 {
+		values, err := drv.GetDistinct(3, 2)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seen := map[int]bool{}
+
+		for _, value := range values {
+			if value == 1 || value == 2 || seen[value] {
+				t.Fatal("excluded, zero weight or repeated value was returned:", values)
+			}
+
+			seen[value] = true
+		}
+	}
+
+	if !slices.Equal(drv.GetWeights(), weights) {
+		t.Fatal("weights should be restored after sampling:", drv.GetWeights())
+	}
+
+	_, err = drv.GetDistinct(5, 2)
+
+	if err != ErrNotEnoughValues {
+		t.Fatal("ErrNotEnoughValues should be returned, got:", err)
+	}
+
+	if !slices.Equal(drv.GetWeights(), weights) {
+		t.Fatal("weights should be restored after failed sampling:", drv.GetWeights())
+	}
+}
+
+func BenchmarkGetDistinct(b *testing.B) {
+	drv, randSource := newBenchmarkVariable(b)
+
+	for b.Loop() {
+		drv.GetDistinct(7, randSource.Intn(BENCHMARK_VALUES_COUNT))
+	}
+}
@@ -2,4 +2,14 @@ package random
 
 import "errors"
 
-var ErrEmptyWeightsSlice = errors.New("weights slice is empty")
+var (
+	ErrEmptyWeightsSlice = errors.New("weights slice is empty")
+
+	ErrNotEnoughValues = errors.New("not enough values with positive weights")
+
+	ErrNegativeWeight = errors.New("weight is negative")
+
+	ErrNotFiniteWeight = errors.New("weight is NaN or infinite")
+
+	ErrZeroTotalWeight = errors.New("all the weights are zero")
+)
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"vocabulary/internal/app"
+)
+
+// A lesson known to the storage.
+type LessonInfo struct {
+	FilePath string
+	Sheet    string
+	LastUTC  time.Time
+	Mode     app.LessonMode
+
+	//Count of phrases with stored statistics and with stored schedule.
+	PhrasesWithProgress uint32
+	PhrasesWithSchedule uint32
+}
+
+// Returns all the stored lessons of the profile, the most recently used first.
+func (s *File) ListLessons(ctx context.Context) ([]LessonInfo, error) {
+	requestText := `
+		SELECT
+			FILE_PATH,
+			FILE_SHEET,
+			DATE_UTC,
+			MODE,
+			(SELECT COUNT(*) FROM LESSONS_PROGRESS WHERE EXCEL_LESSON = EXCEL_LESSONS.ID),
+			(SELECT COUNT(*) FROM LESSONS_SCHEDULE WHERE EXCEL_LESSON = EXCEL_LESSONS.ID)
+		FROM EXCEL_LESSONS
+		WHERE PROFILE = ?
+		ORDER BY DATE_UTC DESC
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res     = []LessonInfo{}
+		lesson  LessonInfo
+		dateUTC string
+	)
+
+	for query.Next() {
+		err = query.Scan(
+			&lesson.FilePath,
+			&lesson.Sheet,
+			&dateUTC,
+			&lesson.Mode,
+			&lesson.PhrasesWithProgress,
+			&lesson.PhrasesWithSchedule,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lesson.LastUTC, err = time.Parse(SQLITE_TIME_FORMAT, dateUTC)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, lesson)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
+
+// Removes stored statistics and schedule of all the phrases of the lesson (or of all
+// the occurrences of the phrase if it isn't empty). Logs of answers, sessions and confusions are kept.
+// Shared knowledge of phrases (see UpsertPhraseKnowledge) is kept too: it is gathered in all the lessons,
+// so answers given in this lesson can't be separated from others.
+// Returns ErrWasNotSaved if there was nothing to remove.
+func (s *File) ResetProgress(ctx context.Context, excelFilePath, sheet, phrase string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	var removed int64
+
+	for _, table := range []string{"LESSONS_PROGRESS", "LESSONS_SCHEDULE"} {
+		requestText := `
+			DELETE FROM ` + table + `
+			WHERE EXCEL_LESSON IN (
+				SELECT ID
+				FROM EXCEL_LESSONS
+				WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+			)
+			AND (? = '' OR PHRASE = ?)
+		`
+
+		requestRes, err := tx.ExecContext(ctx, requestText, s.profileID, excelFilePath, sheet, phrase, phrase)
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+
+		rowsAffected, err := requestRes.RowsAffected()
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+
+		removed += rowsAffected
+	}
+
+	if removed <= 0 {
+		return errors.Join(ErrWasNotSaved, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
+
+// Rebuilds the database file to return the space of removed data to the file system.
+func (s *File) Vacuum(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "VACUUM")
+
+	return err
+}
+
+// Returns problems found by sqlite integrity and foreign keys checks (empty if there are no ones).
+func (s *File) CheckIntegrity(ctx context.Context) ([]string, error) {
+	query, err := s.db.QueryContext(ctx, "PRAGMA integrity_check")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res     = []string{}
+		problem string
+	)
+
+	for query.Next() {
+		err = query.Scan(&problem)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if problem != "ok" {
+			res = append(res, problem)
+		}
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	query, err = s.db.QueryContext(ctx, "PRAGMA foreign_key_check")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		table, parent string
+		rowID         sql.NullInt64
+		foreignKeyID  int64
+	)
+
+	for query.Next() {
+		err = query.Scan(&table, &rowID, &parent, &foreignKeyID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, fmt.Sprintf("row %d of %s references a missing row of %s", rowID.Int64, table, parent))
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestResetProgress(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	err = file.SaveLessonProgress(
+		t.Context(),
+		"words.xlsx",
+		"Unit 1",
+		map[app.PhraseKey]advanced.PhraseLearningStatistics{
+			{Phrase: "bank", Occurrence: 0}: {CountGuessedOOS: 1},
+			{Phrase: "bank", Occurrence: 1}: {CountFailedOOS: 2},
+			{Phrase: "car"}:                 {CountAnsweredTM: 3},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lessons, err := file.ListLessons(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lessons) != 1 || lessons[0].FilePath != "words.xlsx" || lessons[0].Sheet != "Unit 1" || lessons[0].PhrasesWithProgress != 3 {
+		t.Fatal("unexpected lessons:", lessons)
+	}
+
+	err = file.ResetProgress(t.Context(), "words.xlsx", "Unit 1", "bank")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := file.LoadLessonProgress(t.Context(), "words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != 1 || loaded[app.PhraseKey{Phrase: "car"}].CountAnsweredTM != 3 {
+		t.Fatal("all the occurrences of the phrase and only them should be reset:", loaded)
+	}
+
+	err = file.ResetProgress(t.Context(), "words.xlsx", "Unit 1", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.ResetProgress(t.Context(), "words.xlsx", "Unit 1", "")
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatal("reset of the lesson without progress should fail, got:", err)
+	}
+
+	problems, err := file.CheckIntegrity(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) > 0 {
+		t.Fatal("unexpected problems:", problems)
+	}
+}
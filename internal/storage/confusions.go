@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"time"
+	"vocabulary/internal/app"
+)
+
+// Adds confusion.Count to the count of confusions of the phrases and updates the time of the last one.
+// The lesson should be saved earlier (by SaveLastOpen or SaveSession call).
+func (s *File) AddConfusion(ctx context.Context, excelFilePath, sheet string, confusion app.Confusion) error {
+	requestText := `
+		INSERT INTO CONFUSIONS (EXCEL_LESSON, PHRASE, CONFUSED_WITH, COUNT, LAST_UTC)
+		VALUES
+		(
+			(
+				SELECT ID
+				FROM EXCEL_LESSONS
+				WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+			),
+			?, ?, ?, ?
+		)
+		ON CONFLICT (EXCEL_LESSON, PHRASE, CONFUSED_WITH) DO UPDATE SET
+			COUNT = COUNT + excluded.COUNT,
+			LAST_UTC = MAX(LAST_UTC, excluded.LAST_UTC)
+	`
+
+	_, err := s.db.ExecContext(
+		ctx,
+		requestText,
+		s.profileID,
+		excelFilePath,
+		sheet,
+		confusion.Phrase,
+		confusion.ConfusedWith,
+		confusion.Count,
+		confusion.LastTimeUTC.UTC().Format(SQLITE_TIME_FORMAT),
+	)
+
+	return err
+}
+
+// Returns confusions of phrases of the lesson, the most frequent first.
+func (s *File) LoadConfusions(ctx context.Context, excelFilePath, sheet string) ([]app.Confusion, error) {
+	requestText := `
+		SELECT
+			CONFUSIONS.PHRASE,
+			CONFUSIONS.CONFUSED_WITH,
+			CONFUSIONS.COUNT,
+			CONFUSIONS.LAST_UTC
+		FROM EXCEL_LESSONS JOIN CONFUSIONS
+			ON EXCEL_LESSONS.ID = CONFUSIONS.EXCEL_LESSON
+		WHERE
+			EXCEL_LESSONS.PROFILE = ? AND EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
+		ORDER BY CONFUSIONS.COUNT DESC, CONFUSIONS.LAST_UTC DESC
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res       = []app.Confusion{}
+		confusion app.Confusion
+		lastUTC   string
+	)
+
+	for query.Next() {
+		err = query.Scan(&confusion.Phrase, &confusion.ConfusedWith, &confusion.Count, &lastUTC)
+
+		if err != nil {
+			return nil, err
+		}
+
+		confusion.LastTimeUTC, err = time.Parse(SQLITE_TIME_FORMAT, lastUTC)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, confusion)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
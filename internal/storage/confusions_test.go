@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"vocabulary/internal/app"
+)
+
+func TestConfusions(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	err = file.SaveLastOpen(t.Context(), "words.xlsx", "Unit 1", app.LessonModeLern)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		first  = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		second = first.Add(time.Hour)
+	)
+
+	for _, confusion := range []app.Confusion{
+		{Phrase: "car", ConfusedWith: "cat", Count: 1, LastTimeUTC: second},
+		{Phrase: "dog", ConfusedWith: "cat", Count: 1, LastTimeUTC: second},
+		{Phrase: "car", ConfusedWith: "cat", Count: 1, LastTimeUTC: first},
+	} {
+		err = file.AddConfusion(t.Context(), "words.xlsx", "Unit 1", confusion)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	confusions, err := file.LoadConfusions(t.Context(), "words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []app.Confusion{
+		{Phrase: "car", ConfusedWith: "cat", Count: 2, LastTimeUTC: second},
+		{Phrase: "dog", ConfusedWith: "cat", Count: 1, LastTimeUTC: second},
+	}
+
+	if len(confusions) != len(expected) {
+		t.Fatal("unexpected confusions:", confusions)
+	}
+
+	for i := range expected {
+		if confusions[i] != expected[i] {
+			t.Fatal("unexpected confusions:", confusions)
+		}
+	}
+}
@@ -3,4 +3,10 @@ package storage
 const (
 	SQLITE_TIME_FORMAT = "2006-01-02 15:04:05.000"
 	FILE_EXTENTION     = ".sqlite"
+
+	//Count of hashes of rows in a fingerprint of a lesson.
+	FINGERPRINT_SIZE = 128
+
+	//Lessons whose fingerprints are less similar aren't offered for relinking.
+	MIN_RELINK_SIMILARITY = 0.8
 )
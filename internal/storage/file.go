@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 	"vocabulary/internal/app"
@@ -12,14 +13,25 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-var ErrWasNotSaved = errors.New("wasn't saved")
+var (
+	ErrWasNotSaved = errors.New("wasn't saved")
 
+	ErrUnsupportedVersion = errors.New("storage file was created by a newer version of the program")
+
+	ErrEmptyProfileName = errors.New("name of profile is empty")
+)
+
+// All the lessons and their' progress belong to the selected profile (see SelectProfile).
 type File struct {
 	db *sql.DB
+
+	profileID   int64
+	profileName string
 }
 
 // Opens or creates an sqlite database by given file path.
 // If filePath argument doesn't include extention, it will be added.
+// The most recently selected profile is selected.
 func Open(ctx context.Context, filePath string) (*File, error) {
 	if !strings.HasSuffix(filePath, FILE_EXTENTION) {
 		filePath += FILE_EXTENTION
@@ -31,33 +43,7 @@ func Open(ctx context.Context, filePath string) (*File, error) {
 		return nil, err
 	}
 
-	initRequestText := `
-		CREATE TABLE IF NOT EXISTS EXCEL_LESSONS
-		(
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			DATE_UTC TEXT NOT NULL,
-			FILE_PATH TEXT NOT NULL,
-			FILE_SHEET TEXT NOT NULL,
-			MODE INTEGER NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS LESSONS_PROGRESS
-		(
-			EXCEL_LESSON INTEGER NOT NULL,
-			PHRASE TEXT NOT NULL,
-			COUNT_GUESSED_OOS INTEGER NOT NULL,
-			COUNT_FAILED_OOS INTEGER NOT NULL,
-			COUNT_ANSWERED_TM INTEGER NOT NULL,
-			COUNT_FAILED_TM INTEGER NOT NULL,
-			COUNT_GUESSED_OOS_INVERTED INTEGER NOT NULL,
-			COUNT_FAILED_OOS_INVERTED INTEGER NOT NULL,
-			COUNT_ANSWERED_TM_INVERTED INTEGER NOT NULL,
-			COUNT_FAILED_TM_INVERTED INTEGER NOT NULL,
-			FOREIGN KEY (EXCEL_LESSON) REFERENCES EXCEL_LESSONS(ID) ON DELETE CASCADE
-		);
-	`
-
-	_, err = db.Exec(initRequestText)
+	err = migrate(ctx, db)
 
 	if err != nil {
 		db.Close()
@@ -69,11 +55,19 @@ func Open(ctx context.Context, filePath string) (*File, error) {
 		db: db,
 	}
 
+	err = res.selectLastProfile(ctx)
+
+	if err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
 	return res, nil
 }
 
 func (s *File) SaveLastOpen(ctx context.Context, excelFilePath, sheet string, mode app.LessonMode) error {
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 
 	if err != nil {
 		return err
@@ -94,11 +88,12 @@ func (s *File) updateExcelLessonDateOrAddExcelLesson(ctx context.Context, tx *sq
 	requestText := `
 		UPDATE EXCEL_LESSONS
 		SET DATE_UTC = ?, MODE = ?
-		WHERE FILE_PATH = ?
+		WHERE PROFILE = ?
+		AND FILE_PATH = ?
 		AND FILE_SHEET = ?
 	`
 
-	requestRes, err := tx.ExecContext(ctx, requestText, timeUTC, mode, excelFilePath, sheet)
+	requestRes, err := tx.ExecContext(ctx, requestText, timeUTC, mode, s.profileID, excelFilePath, sheet)
 
 	if err != nil {
 		return err
@@ -112,11 +107,11 @@ func (s *File) updateExcelLessonDateOrAddExcelLesson(ctx context.Context, tx *sq
 
 	if rowsAffected <= 0 {
 		requestText = `
-			INSERT INTO EXCEL_LESSONS (DATE_UTC, FILE_PATH, FILE_SHEET, MODE) VALUES
-			(?, ?, ?, ?)
+			INSERT INTO EXCEL_LESSONS (PROFILE, DATE_UTC, FILE_PATH, FILE_SHEET, MODE) VALUES
+			(?, ?, ?, ?, ?)
 		`
 
-		_, err = tx.ExecContext(ctx, requestText, timeUTC, excelFilePath, sheet, mode)
+		_, err = tx.ExecContext(ctx, requestText, s.profileID, timeUTC, excelFilePath, sheet, mode)
 
 		if err != nil {
 			return err
@@ -126,15 +121,62 @@ func (s *File) updateExcelLessonDateOrAddExcelLesson(ctx context.Context, tx *sq
 	return nil
 }
 
+// Unlike updateExcelLessonDateOrAddExcelLesson, doesn't change the mode of the stored lesson:
+// progress is the same in learning and spelling modes. The mode is used if the lesson is added.
+func (s *File) touchExcelLesson(ctx context.Context, tx *sql.Tx, excelFilePath, sheet string, mode app.LessonMode) error {
+	requestText := `
+		UPDATE EXCEL_LESSONS
+		SET DATE_UTC = ?
+		WHERE PROFILE = ?
+		AND FILE_PATH = ?
+		AND FILE_SHEET = ?
+	`
+
+	requestRes, err := tx.ExecContext(ctx, requestText, time.Now().UTC().Format(SQLITE_TIME_FORMAT), s.profileID, excelFilePath, sheet)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := requestRes.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	return s.updateExcelLessonDateOrAddExcelLesson(ctx, tx, excelFilePath, sheet, mode)
+}
+
+func (s *File) getExcelLessonID(ctx context.Context, tx *sql.Tx, excelFilePath, sheet string) (int64, error) {
+	requestText := `
+		SELECT ID
+		FROM EXCEL_LESSONS
+		WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+	`
+
+	row := tx.QueryRowContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	var lessonID int64
+
+	err := row.Scan(&lessonID)
+
+	return lessonID, err
+}
+
 func (s *File) LoadLastOpen(ctx context.Context) (excelFilePath, sheet string, mode app.LessonMode, err error) {
 	requestText := `
 		SELECT FILE_PATH, FILE_SHEET, MODE
 		FROM EXCEL_LESSONS
+		WHERE PROFILE = ?
 		ORDER BY DATE_UTC DESC
 		LIMIT 1
 	`
 
-	row := s.db.QueryRowContext(ctx, requestText)
+	row := s.db.QueryRowContext(ctx, requestText, s.profileID)
 
 	err = row.Scan(&excelFilePath, &sheet, &mode)
 
@@ -151,10 +193,10 @@ func (s *File) SavedProgressAvailable(ctx context.Context, excelFilePath, sheet
 		FROM EXCEL_LESSONS JOIN LESSONS_PROGRESS
 			ON EXCEL_LESSONS.ID = LESSONS_PROGRESS.EXCEL_LESSON
 		WHERE
-			EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
+			EXCEL_LESSONS.PROFILE = ? AND EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
 	`
 
-	row := s.db.QueryRowContext(ctx, requestText, excelFilePath, sheet)
+	row := s.db.QueryRowContext(ctx, requestText, s.profileID, excelFilePath, sheet)
 
 	res := false
 
@@ -171,9 +213,9 @@ func (s *File) SaveLessonProgress(
 	ctx context.Context,
 	excelFilePath string,
 	sheet string,
-	statisticsByPhrase map[string]advanced.PhraseLearningStatistics,
+	statisticsByPhrase map[app.PhraseKey]advanced.PhraseLearningStatistics,
 ) error {
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 
 	if err != nil {
 		return err
@@ -184,43 +226,113 @@ func (s *File) SaveLessonProgress(
 		WHERE EXCEL_LESSON IN (
 			SELECT ID
 			FROM EXCEL_LESSONS
-			WHERE FILE_PATH = ? AND FILE_SHEET = ?
+			WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
 		)
 	`
 
-	_, err = tx.ExecContext(ctx, requestText, excelFilePath, sheet)
+	_, err = tx.ExecContext(ctx, requestText, s.profileID, excelFilePath, sheet)
 
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
 
-	err = s.updateExcelLessonDateOrAddExcelLesson(ctx, tx, excelFilePath, sheet, app.LessonModeLern)
+	err = s.touchExcelLesson(ctx, tx, excelFilePath, sheet, app.LessonModeLern)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	lessonID, err := s.getExcelLessonID(ctx, tx, excelFilePath, sheet)
 
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
 
 	requestText = `
-		SELECT ID
-		FROM EXCEL_LESSONS
-		WHERE FILE_PATH = ? AND FILE_SHEET = ?
+		INSERT INTO LESSONS_PROGRESS
+		(
+			EXCEL_LESSON,
+			PHRASE,
+			OCCURRENCE,
+			COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED
+		)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	row := tx.QueryRowContext(ctx, requestText, excelFilePath, sheet)
+	preparedRequest, err := tx.PrepareContext(ctx, requestText)
 
-	var lessonID int64
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
 
-	err = row.Scan(&lessonID)
+	for key, stats := range statisticsByPhrase {
+		_, err = preparedRequest.ExecContext(
+			ctx,
+			lessonID,
+			key.Phrase,
+			key.Occurrence,
+			stats.CountGuessedOOS,
+			stats.CountFailedOOS,
+			stats.CountAnsweredTM,
+			stats.CountFailedTM,
+			stats.CountGuessedOOSInverted,
+			stats.CountFailedOOSInverted,
+			stats.CountAnsweredTMInverted,
+			stats.CountFailedTMInverted,
+			stats.CountAlmostTM,
+			stats.CountAlmostTMInverted,
+		)
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Unlike SaveLessonProgress, doesn't remove stored statistics of other phrases of the lesson.
+// Used to store changes of progress during the lesson.
+func (s *File) UpsertLessonProgress(
+	ctx context.Context,
+	excelFilePath string,
+	sheet string,
+	statisticsByPhrase map[app.PhraseKey]advanced.PhraseLearningStatistics,
+) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.touchExcelLesson(ctx, tx, excelFilePath, sheet, app.LessonModeLern)
 
 	if err != nil {
 		return errors.Join(err, tx.Rollback())
 	}
 
-	requestText = `
+	lessonID, err := s.getExcelLessonID(ctx, tx, excelFilePath, sheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	requestText := `
 		INSERT INTO LESSONS_PROGRESS
 		(
 			EXCEL_LESSON,
 			PHRASE,
+			OCCURRENCE,
 			COUNT_GUESSED_OOS,
 			COUNT_FAILED_OOS,
 			COUNT_ANSWERED_TM,
@@ -228,10 +340,23 @@ func (s *File) SaveLessonProgress(
 			COUNT_GUESSED_OOS_INVERTED,
 			COUNT_FAILED_OOS_INVERTED,
 			COUNT_ANSWERED_TM_INVERTED,
-			COUNT_FAILED_TM_INVERTED
+			COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED
 		)
 		VALUES
-			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (EXCEL_LESSON, PHRASE, OCCURRENCE) DO UPDATE SET
+			COUNT_GUESSED_OOS = excluded.COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS = excluded.COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM = excluded.COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM = excluded.COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED = excluded.COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED = excluded.COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED = excluded.COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED = excluded.COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM = excluded.COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED = excluded.COUNT_ALMOST_TM_INVERTED
 	`
 
 	preparedRequest, err := tx.PrepareContext(ctx, requestText)
@@ -240,11 +365,12 @@ func (s *File) SaveLessonProgress(
 		return errors.Join(err, tx.Rollback())
 	}
 
-	for phrase, stats := range statisticsByPhrase {
+	for key, stats := range statisticsByPhrase {
 		_, err = preparedRequest.ExecContext(
 			ctx,
 			lessonID,
-			phrase,
+			key.Phrase,
+			key.Occurrence,
 			stats.CountGuessedOOS,
 			stats.CountFailedOOS,
 			stats.CountAnsweredTM,
@@ -253,6 +379,8 @@ func (s *File) SaveLessonProgress(
 			stats.CountFailedOOSInverted,
 			stats.CountAnsweredTMInverted,
 			stats.CountFailedTMInverted,
+			stats.CountAlmostTM,
+			stats.CountAlmostTMInverted,
 		)
 
 		if err != nil {
@@ -268,12 +396,13 @@ func (s *File) LoadLessonProgress(
 	excelFilePath,
 	sheet string,
 ) (
-	statisticsByPhrase map[string]advanced.PhraseLearningStatistics,
+	statisticsByPhrase map[app.PhraseKey]advanced.PhraseLearningStatistics,
 	err error,
 ) {
 	requestText := `
 		SELECT
 			LESSONS_PROGRESS.PHRASE,
+			LESSONS_PROGRESS.OCCURRENCE,
 			LESSONS_PROGRESS.COUNT_GUESSED_OOS,
 			LESSONS_PROGRESS.COUNT_FAILED_OOS,
 			LESSONS_PROGRESS.COUNT_ANSWERED_TM,
@@ -281,14 +410,16 @@ func (s *File) LoadLessonProgress(
 			LESSONS_PROGRESS.COUNT_GUESSED_OOS_INVERTED,
 			LESSONS_PROGRESS.COUNT_FAILED_OOS_INVERTED,
 			LESSONS_PROGRESS.COUNT_ANSWERED_TM_INVERTED,
-			LESSONS_PROGRESS.COUNT_FAILED_TM_INVERTED
+			LESSONS_PROGRESS.COUNT_FAILED_TM_INVERTED,
+			LESSONS_PROGRESS.COUNT_ALMOST_TM,
+			LESSONS_PROGRESS.COUNT_ALMOST_TM_INVERTED
 		FROM EXCEL_LESSONS JOIN LESSONS_PROGRESS
 			ON EXCEL_LESSONS.ID = LESSONS_PROGRESS.EXCEL_LESSON
 		WHERE
-			EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
+			EXCEL_LESSONS.PROFILE = ? AND EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
 	`
 
-	query, err := s.db.QueryContext(ctx, requestText, excelFilePath, sheet)
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, sheet)
 
 	if err != nil {
 		return nil, err
@@ -297,14 +428,15 @@ func (s *File) LoadLessonProgress(
 	defer query.Close()
 
 	var (
-		res    = map[string]advanced.PhraseLearningStatistics{}
-		stats  advanced.PhraseLearningStatistics
-		phrase string
+		res   = map[app.PhraseKey]advanced.PhraseLearningStatistics{}
+		stats advanced.PhraseLearningStatistics
+		key   app.PhraseKey
 	)
 
 	for query.Next() {
 		err = query.Scan(
-			&phrase,
+			&key.Phrase,
+			&key.Occurrence,
 			&stats.CountGuessedOOS,
 			&stats.CountFailedOOS,
 			&stats.CountAnsweredTM,
@@ -313,13 +445,15 @@ func (s *File) LoadLessonProgress(
 			&stats.CountFailedOOSInverted,
 			&stats.CountAnsweredTMInverted,
 			&stats.CountFailedTMInverted,
+			&stats.CountAlmostTM,
+			&stats.CountAlmostTMInverted,
 		)
 
 		if err != nil {
 			return nil, err
 		}
 
-		res[phrase] = stats
+		res[key] = stats
 	}
 
 	if query.Err() != nil {
@@ -333,11 +467,38 @@ func (s *File) LoadLessonProgress(
 	return res, nil
 }
 
-// Removes all the data associated with lessons which were used earlier than excelLessonsHistoryPeriodBeginning.
+// Tables with data of lessons and their' columns with ID of lesson.
+// Tables which reference EXCEL_LESSONS should be cleaned before it.
+// REVIEWS isn't here: the log of answers is append-only (statistics can be recalculated by it),
+// so it is never removed, and lessons which it references are kept too.
+var excelLessonsTables = [][2]string{
+	{"LESSONS_PROGRESS", "EXCEL_LESSON"},
+	{"LESSONS_SCHEDULE", "EXCEL_LESSON"},
+	{"SESSIONS", "EXCEL_LESSON"},
+	{"CONFUSIONS", "EXCEL_LESSON"},
+	{"EXCEL_LESSONS", "ID"},
+}
+
+// Condition of removal of rows of EXCEL_LESSONS (see excelLessonsTables).
+const excelLessonWithoutReviews = `
+	NOT EXISTS (SELECT 1 FROM REVIEWS WHERE REVIEWS.EXCEL_LESSON = EXCEL_LESSONS.ID)
+`
+
+// Returns the condition of removal of rows of the table, in addition to the ID of lesson.
+func excelLessonsTableCondition(table string) string {
+	if table == "EXCEL_LESSONS" {
+		return excelLessonWithoutReviews
+	}
+
+	return "1"
+}
+
+// Removes all the data associated with lessons which were used earlier than excelLessonsHistoryPeriodBeginning
+// excluding logs of answers (see excelLessonsTables).
 // Removes lesson if only it's number (by the order of decreasing last usage date) is bigger than maxLessonsCount.
-// Uses FIFO discipline.
+// Uses FIFO discipline. Lessons of each profile are counted separately.
 func (s *File) EraseOutdatedData(ctx context.Context, maxLessonsCount uint32, excelLessonsHistoryPeriodBeginning time.Time) error {
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 
 	if err != nil {
 		return err
@@ -345,10 +506,10 @@ func (s *File) EraseOutdatedData(ctx context.Context, maxLessonsCount uint32, ex
 
 	periodInSQLiteFormat := excelLessonsHistoryPeriodBeginning.Format(SQLITE_TIME_FORMAT)
 
-	requestText := `
+	requestTextFormat := `
 		WITH
 			NUMBERED AS (
-				SELECT ID, DATE_UTC, ROW_NUMBER() OVER (ORDER BY DATE_UTC DESC) AS RN
+				SELECT ID, DATE_UTC, ROW_NUMBER() OVER (PARTITION BY PROFILE ORDER BY DATE_UTC DESC) AS RN
 				FROM EXCEL_LESSONS
 			),
 
@@ -358,40 +519,46 @@ func (s *File) EraseOutdatedData(ctx context.Context, maxLessonsCount uint32, ex
 				WHERE RN > ? AND DATE_UTC < ?
 			)
 
-		DELETE FROM LESSONS_PROGRESS
-		WHERE EXCEL_LESSON IN TO_DELETE
+		DELETE FROM %s
+		WHERE %s IN TO_DELETE AND %s
 	`
 
-	_, err = tx.ExecContext(ctx, requestText, maxLessonsCount, periodInSQLiteFormat)
+	for _, tableAndColumn := range excelLessonsTables {
+		requestText := fmt.Sprintf(requestTextFormat, tableAndColumn[0], tableAndColumn[1], excelLessonsTableCondition(tableAndColumn[0]))
 
-	if err != nil {
-		return errors.Join(err, tx.Rollback())
-	}
+		_, err = tx.ExecContext(ctx, requestText, maxLessonsCount, periodInSQLiteFormat)
 
-	requestText = `
-		WITH
-			NUMBERED AS (
-				SELECT ID, DATE_UTC, ROW_NUMBER() OVER (ORDER BY DATE_UTC DESC) AS RN
-				FROM EXCEL_LESSONS
-			),
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
 
-			TO_DELETE AS (
-				SELECT ID
-				FROM NUMBERED
-				WHERE RN > ? AND DATE_UTC < ?
-			)
+	return tx.Commit()
+}
 
-		DELETE FROM EXCEL_LESSONS
-		WHERE ID IN TO_DELETE
+// Removes all the data of the lesson excluding the log of answers (see excelLessonsTables).
+func (s *File) deleteExcelLesson(ctx context.Context, tx *sql.Tx, excelFilePath, sheet string) error {
+	requestTextFormat := `
+		DELETE FROM %s
+		WHERE %s IN (
+			SELECT ID
+			FROM EXCEL_LESSONS
+			WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+		)
+		AND %s
 	`
 
-	_, err = tx.ExecContext(ctx, requestText, maxLessonsCount, periodInSQLiteFormat)
+	for _, tableAndColumn := range excelLessonsTables {
+		requestText := fmt.Sprintf(requestTextFormat, tableAndColumn[0], tableAndColumn[1], excelLessonsTableCondition(tableAndColumn[0]))
 
-	if err != nil {
-		return errors.Join(err, tx.Rollback())
+		_, err := tx.ExecContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+		if err != nil {
+			return err
+		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (s *File) Close() error {
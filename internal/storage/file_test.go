@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestProgressOfDuplicatePhrases(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	progress := map[app.PhraseKey]advanced.PhraseLearningStatistics{
+		{Phrase: "bank", Occurrence: 0}: {CountGuessedOOS: 1},
+		{Phrase: "bank", Occurrence: 1}: {CountFailedOOS: 2},
+	}
+
+	err = file.SaveLessonProgress(t.Context(), "words.xlsx", "Unit 1", progress)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.UpsertLessonProgress(
+		t.Context(),
+		"words.xlsx",
+		"Unit 1",
+		map[app.PhraseKey]advanced.PhraseLearningStatistics{
+			{Phrase: "bank", Occurrence: 1}: {CountFailedOOS: 3},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress[app.PhraseKey{Phrase: "bank", Occurrence: 1}] = advanced.PhraseLearningStatistics{CountFailedOOS: 3}
+
+	loaded, err := file.LoadLessonProgress(t.Context(), "words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !maps.Equal(loaded, progress) {
+		t.Fatal("progress of duplicate phrases should be stored separately:", loaded)
+	}
+}
@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Content fingerprint of a lesson: FINGERPRINT_SIZE smallest hashes of its' distinct rows
+// (bottom-k sketch). Lessons with similar fingerprints have mostly the same rows,
+// even if some rows were added, removed or reordered.
+type Fingerprint []uint64
+
+// Returns the fingerprint of rows of a lesson (each row is the text of its' cells).
+func NewFingerprint(rows []string) Fingerprint {
+	res := make(Fingerprint, 0, len(rows))
+
+	for _, row := range rows {
+		hash := fnv.New64a()
+
+		hash.Write([]byte(row))
+
+		res = append(res, hash.Sum64())
+	}
+
+	slices.Sort(res)
+
+	res = slices.Compact(res)
+
+	return res[:min(len(res), FINGERPRINT_SIZE)]
+}
+
+// Returns an estimation of the share of common rows among all rows of both lessons (Jaccard index).
+// It is exact for lessons with less than FINGERPRINT_SIZE rows.
+func (f Fingerprint) Similarity(other Fingerprint) float64 {
+	var (
+		i, j          int
+		union, common int
+	)
+
+	//The smallest hashes of the union of rows are a random sample of it.
+	for union < FINGERPRINT_SIZE && (i < len(f) || j < len(other)) {
+		switch {
+		case j >= len(other) || (i < len(f) && f[i] < other[j]):
+			i++
+		case i >= len(f) || other[j] < f[i]:
+			j++
+		default:
+			common++
+			i++
+			j++
+		}
+
+		union++
+	}
+
+	if union <= 0 {
+		return 0
+	}
+
+	return float64(common) / float64(union)
+}
+
+func (f Fingerprint) String() string {
+	hashes := make([]string, len(f))
+
+	for i, hash := range f {
+		hashes[i] = strconv.FormatUint(hash, 16)
+	}
+
+	return strings.Join(hashes, " ")
+}
+
+func parseFingerprint(s string) (Fingerprint, error) {
+	fields := strings.Fields(s)
+
+	res := make(Fingerprint, len(fields))
+
+	for i, field := range fields {
+		hash, err := strconv.ParseUint(field, 16, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("fingerprint: %w", err)
+		}
+
+		res[i] = hash
+	}
+
+	return res, nil
+}
+
+// A stored lesson whose content is similar to the content of another lesson.
+type RelinkCandidate struct {
+	FilePath   string
+	Sheet      string
+	Similarity float64
+}
+
+// Stores the fingerprint of the lesson content. Should be called after SaveLastOpen or SaveSession.
+func (s *File) SaveFingerprint(ctx context.Context, excelFilePath, sheet string, fingerprint Fingerprint) error {
+	requestText := `
+		UPDATE EXCEL_LESSONS
+		SET FINGERPRINT = ?
+		WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, requestText, fingerprint.String(), s.profileID, excelFilePath, sheet)
+
+	return err
+}
+
+// Returns lessons with progress whose content is similar to the content of the lesson (the most similar first),
+// if it has no progress of its' own. For example, it is so when the file of the lesson was moved or renamed.
+func (s *File) FindRelinkCandidates(ctx context.Context, excelFilePath, sheet string, fingerprint Fingerprint) ([]RelinkCandidate, error) {
+	requestText := `
+		SELECT FILE_PATH, FILE_SHEET, FINGERPRINT
+		FROM EXCEL_LESSONS
+		WHERE
+			PROFILE = ?
+			AND FINGERPRINT != ''
+			AND NOT (FILE_PATH = ? AND FILE_SHEET = ?)
+			AND (
+				EXISTS (SELECT 1 FROM LESSONS_PROGRESS WHERE EXCEL_LESSON = EXCEL_LESSONS.ID)
+				OR EXISTS (SELECT 1 FROM LESSONS_SCHEDULE WHERE EXCEL_LESSON = EXCEL_LESSONS.ID)
+			)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM EXCEL_LESSONS AS SAME
+				WHERE
+					SAME.PROFILE = EXCEL_LESSONS.PROFILE AND SAME.FILE_PATH = ? AND SAME.FILE_SHEET = ?
+					AND (
+						EXISTS (SELECT 1 FROM LESSONS_PROGRESS WHERE EXCEL_LESSON = SAME.ID)
+						OR EXISTS (SELECT 1 FROM LESSONS_SCHEDULE WHERE EXCEL_LESSON = SAME.ID)
+					)
+			)
+		ORDER BY DATE_UTC DESC
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, sheet, excelFilePath, sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res               = []RelinkCandidate{}
+		candidate         RelinkCandidate
+		storedFingerprint string
+	)
+
+	for query.Next() {
+		err = query.Scan(&candidate.FilePath, &candidate.Sheet, &storedFingerprint)
+
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseFingerprint(storedFingerprint)
+
+		if err != nil {
+			return nil, err
+		}
+
+		candidate.Similarity = fingerprint.Similarity(parsed)
+
+		if candidate.Similarity >= MIN_RELINK_SIMILARITY {
+			res = append(res, candidate)
+		}
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	//The most recently used lesson is the first among equally similar ones.
+	slices.SortStableFunc(
+		res,
+		func(a, b RelinkCandidate) int {
+			return cmp.Compare(b.Similarity, a.Similarity)
+		},
+	)
+
+	return res, nil
+}
+
+// Moves all the data of the lesson of one file and sheet to another one.
+// Data stored for the new file and sheet earlier (without progress) is removed,
+// excluding answers logged in it: they are moved to the moved lesson.
+func (s *File) Relink(ctx context.Context, fromExcelFilePath, fromSheet, toExcelFilePath, toSheet string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	fromLessonID, err := s.getExcelLessonID(ctx, tx, fromExcelFilePath, fromSheet)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.Join(ErrWasNotSaved, tx.Rollback())
+	}
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	requestText := `
+		UPDATE REVIEWS
+		SET EXCEL_LESSON = ?
+		WHERE EXCEL_LESSON IN (
+			SELECT ID
+			FROM EXCEL_LESSONS
+			WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+		)
+	`
+
+	_, err = tx.ExecContext(ctx, requestText, fromLessonID, s.profileID, toExcelFilePath, toSheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = s.deleteExcelLesson(ctx, tx, toExcelFilePath, toSheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	requestText = `
+		UPDATE EXCEL_LESSONS
+		SET FILE_PATH = ?, FILE_SHEET = ?
+		WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+	`
+
+	_, err = tx.ExecContext(ctx, requestText, toExcelFilePath, toSheet, s.profileID, fromExcelFilePath, fromSheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func fingerprintOfRange(begin, end int) Fingerprint {
+	rows := []string{}
+
+	for i := begin; i < end; i++ {
+		rows = append(rows, fmt.Sprintf("word%d\tслово%d", i, i))
+	}
+
+	return NewFingerprint(rows)
+}
+
+func TestFingerprintSimilarity(t *testing.T) {
+	for _, testCase := range []struct {
+		a, b     Fingerprint
+		expected float64
+		delta    float64
+	}{
+		{fingerprintOfRange(0, 10), fingerprintOfRange(0, 10), 1, 0},
+		{fingerprintOfRange(0, 10), fingerprintOfRange(5, 15), 5.0 / 15, 0},
+		{fingerprintOfRange(0, 10), fingerprintOfRange(10, 20), 0, 0},
+		{fingerprintOfRange(0, 2000), fingerprintOfRange(0, 1800), 0.9, 0.1},
+		{fingerprintOfRange(0, 2000), fingerprintOfRange(1000, 3000), 1.0 / 3, 0.1},
+	} {
+		similarity := testCase.a.Similarity(testCase.b)
+
+		if math.Abs(similarity-testCase.expected) > testCase.delta+1e-9 {
+			t.Fatalf("expected similarity %f, got %f", testCase.expected, similarity)
+		}
+	}
+
+	parsed, err := parseFingerprint(fingerprintOfRange(0, 10).String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Similarity(fingerprintOfRange(0, 10)) != 1 {
+		t.Fatal("fingerprint should be the same after parsing")
+	}
+}
+
+func TestRelink(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	err = file.SaveLessonProgress(
+		t.Context(),
+		"/old/words.xlsx",
+		"Unit 1",
+		map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "word1"}: {CountGuessedOOS: 1}},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.SaveFingerprint(t.Context(), "/old/words.xlsx", "Unit 1", fingerprintOfRange(0, 20))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//A row was added to the moved file.
+	candidates, err := file.FindRelinkCandidates(t.Context(), "/new/words.xlsx", "Unit 1", fingerprintOfRange(0, 21))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(candidates) != 1 || candidates[0].FilePath != "/old/words.xlsx" || candidates[0].Sheet != "Unit 1" {
+		t.Fatal("unexpected candidates:", candidates)
+	}
+
+	candidates, err = file.FindRelinkCandidates(t.Context(), "/new/words.xlsx", "Unit 1", fingerprintOfRange(10, 30))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(candidates) > 0 {
+		t.Fatal("lessons with different content shouldn't be candidates:", candidates)
+	}
+
+	//The moved file was opened before relinking.
+	err = file.SaveLastOpen(t.Context(), "/new/words.xlsx", "Unit 1", app.LessonModeLern)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.Relink(t.Context(), "/old/words.xlsx", "Unit 1", "/new/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := file.LoadLessonProgress(t.Context(), "/new/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded[app.PhraseKey{Phrase: "word1"}].CountGuessedOOS != 1 {
+		t.Fatal("progress should be relinked:", loaded)
+	}
+
+	lessons, err := file.ListLessons(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lessons) != 1 {
+		t.Fatal("unexpected lessons after relinking:", lessons)
+	}
+
+	candidates, err = file.FindRelinkCandidates(t.Context(), "/new/words.xlsx", "Unit 1", fingerprintOfRange(0, 20))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(candidates) > 0 {
+		t.Fatal("lessons with progress shouldn't get candidates:", candidates)
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"vocabulary/internal/app/advanced"
+)
+
+// Returns stored shared statistics of the phrases in the profile (absent phrases have no statistics).
+func (s *File) LoadPhraseKnowledge(ctx context.Context, keys []advanced.KnowledgeKey) (map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics, error) {
+	requestText := `
+		SELECT
+			COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED
+		FROM PHRASE_KNOWLEDGE
+		WHERE PROFILE = ? AND PHRASE = ? AND TRANSLATION = ?
+	`
+
+	preparedRequest, err := s.db.PrepareContext(ctx, requestText)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer preparedRequest.Close()
+
+	res := map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics{}
+
+	for _, key := range keys {
+		var stats advanced.PhraseLearningStatistics
+
+		err = preparedRequest.QueryRowContext(ctx, s.profileID, key.Phrase, key.Translation).Scan(
+			&stats.CountGuessedOOS,
+			&stats.CountFailedOOS,
+			&stats.CountAnsweredTM,
+			&stats.CountFailedTM,
+			&stats.CountGuessedOOSInverted,
+			&stats.CountFailedOOSInverted,
+			&stats.CountAnsweredTMInverted,
+			&stats.CountFailedTMInverted,
+			&stats.CountAlmostTM,
+			&stats.CountAlmostTMInverted,
+		)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res[key] = stats
+	}
+
+	return res, nil
+}
+
+// Adds statistics of answers to phrases into shared ones. Only answers given since the previous call
+// should be passed (not the whole statistics of phrases in a lesson), so answers given in different
+// lessons are all counted and no answer is counted twice.
+func (s *File) UpsertPhraseKnowledge(ctx context.Context, statisticsByPhrase map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	requestText := `
+		INSERT INTO PHRASE_KNOWLEDGE
+		(
+			PROFILE,
+			PHRASE,
+			TRANSLATION,
+			COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED,
+			DATE_UTC
+		)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (PROFILE, PHRASE, TRANSLATION) DO UPDATE SET
+			COUNT_GUESSED_OOS = COUNT_GUESSED_OOS + excluded.COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS = COUNT_FAILED_OOS + excluded.COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM = COUNT_ANSWERED_TM + excluded.COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM = COUNT_FAILED_TM + excluded.COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED = COUNT_GUESSED_OOS_INVERTED + excluded.COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED = COUNT_FAILED_OOS_INVERTED + excluded.COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED = COUNT_ANSWERED_TM_INVERTED + excluded.COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED = COUNT_FAILED_TM_INVERTED + excluded.COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM = COUNT_ALMOST_TM + excluded.COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED = COUNT_ALMOST_TM_INVERTED + excluded.COUNT_ALMOST_TM_INVERTED,
+			DATE_UTC = excluded.DATE_UTC
+	`
+
+	preparedRequest, err := tx.PrepareContext(ctx, requestText)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	timeUTC := time.Now().UTC().Format(SQLITE_TIME_FORMAT)
+
+	for key, stats := range statisticsByPhrase {
+		_, err = preparedRequest.ExecContext(
+			ctx,
+			s.profileID,
+			key.Phrase,
+			key.Translation,
+			stats.CountGuessedOOS,
+			stats.CountFailedOOS,
+			stats.CountAnsweredTM,
+			stats.CountFailedTM,
+			stats.CountGuessedOOSInverted,
+			stats.CountFailedOOSInverted,
+			stats.CountAnsweredTMInverted,
+			stats.CountFailedTMInverted,
+			stats.CountAlmostTM,
+			stats.CountAlmostTMInverted,
+			timeUTC,
+		)
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestPhraseKnowledge(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	var (
+		car = advanced.KnowledgeKey{Phrase: "car", Translation: "машина"}
+		cat = advanced.KnowledgeKey{Phrase: "cat", Translation: "кошка"}
+		dog = advanced.KnowledgeKey{Phrase: "dog", Translation: "собака"}
+	)
+
+	for _, knowledge := range []map[advanced.KnowledgeKey]advanced.PhraseLearningStatistics{
+		{car: {CountGuessedOOS: 3, CountFailedTM: 1}, cat: {CountAnsweredTM: 1}},
+		{car: {CountGuessedOOS: 1, CountFailedTM: 2}},
+	} {
+		err = file.UpsertPhraseKnowledge(t.Context(), knowledge)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := file.LoadPhraseKnowledge(t.Context(), []advanced.KnowledgeKey{car, dog})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != 1 {
+		t.Fatal("only requested stored phrases should be loaded:", loaded)
+	}
+
+	if loaded[car] != (advanced.PhraseLearningStatistics{CountGuessedOOS: 4, CountFailedTM: 3}) {
+		t.Fatal("counters should be summed:", loaded[car])
+	}
+}
@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// How a lesson of the merged storage was matched with a lesson of this one.
+type LessonMatch byte
+
+const (
+	//The lesson is new to this storage, it is added.
+	LessonMatchNone LessonMatch = iota
+
+	//This storage has a lesson with the same file path and sheet.
+	LessonMatchPath
+
+	//This storage has a lesson with similar content (see Fingerprint) or, if the content
+	//of the merged lesson wasn't stored, with the same phrases in progress
+	//(for example, the workbook has another path on the other machine).
+	LessonMatchFingerprint
+)
+
+// Result of merging of a lesson from another storage.
+type MergedLesson struct {
+	//The lesson in the merged storage.
+	FilePath string
+	Sheet    string
+
+	//The lesson in this storage which received the progress.
+	TargetFilePath string
+	TargetSheet    string
+
+	Match LessonMatch
+
+	//Counts of phrases whose progress was added or merged with stored one.
+	Added  int
+	Merged int
+}
+
+type lessonProgress struct {
+	records     []ProgressRecord
+	fingerprint string
+}
+
+type lessonFingerprint struct {
+	filePath    string
+	sheet       string
+	fingerprint Fingerprint
+}
+
+// Merges progress of lessons stored in another storage file into this one. Lessons are matched
+// by the file path and the sheet, otherwise by the stored fingerprint of their' content (the most
+// similar lesson is chosen among ones with Similarity not less than MIN_RELINK_SIMILARITY).
+// Lessons without stored content are matched by the fingerprint of phrases with progress (the most
+// recently used lesson is chosen among lessons with the same one). Other lessons are added.
+// Conflicting counters are merged by the policy. If dryRun is true, nothing is changed,
+// but the report is the same. The other file is never changed: it is read only
+// and a temporary copy of it is migrated to the current schema version if needed.
+// Only progress of the profile with the same name as the selected one is merged.
+func (s *File) Merge(ctx context.Context, otherFilePath string, policy ConflictPolicy, dryRun bool) ([]MergedLesson, error) {
+	if !strings.HasSuffix(otherFilePath, FILE_EXTENTION) {
+		otherFilePath += FILE_EXTENTION
+	}
+
+	//Otherwise an empty database would be created.
+	_, err := os.Stat(otherFilePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "vocabulary-merge-")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(tempDir)
+
+	other, err := openCopy(ctx, otherFilePath, filepath.Join(tempDir, "other"+FILE_EXTENTION))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer other.Close()
+
+	err = other.UseProfile(ctx, s.profileName)
+
+	if errors.Is(err, ErrWasNotSaved) {
+		return []MergedLesson{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	otherRecords, err := other.ExportProgress(ctx, "", "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	storedRecords, err := s.ExportProgress(ctx, "", "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	storedLessons, err := s.ListLessons(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	otherFingerprints, err := other.lessonFingerprints(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	otherFingerprintsByPath := map[[2]string]Fingerprint{}
+
+	for _, lesson := range otherFingerprints {
+		otherFingerprintsByPath[[2]string{lesson.filePath, lesson.sheet}] = lesson.fingerprint
+	}
+
+	storedFingerprints, err := s.lessonFingerprints(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lessonsByPath        = map[[2]string]bool{}
+		lessonsByFingerprint = map[string]*ProgressRecord{}
+	)
+
+	for _, lesson := range storedLessons {
+		lessonsByPath[[2]string{lesson.FilePath, lesson.Sheet}] = true
+	}
+
+	for _, lesson := range groupByLesson(storedRecords) {
+		found, exists := lessonsByFingerprint[lesson.fingerprint]
+
+		if !exists || lesson.records[0].LessonUTC.After(found.LessonUTC) {
+			lessonsByFingerprint[lesson.fingerprint] = &lesson.records[0]
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := []MergedLesson{}
+
+	for _, lesson := range groupByLesson(otherRecords) {
+		merged := MergedLesson{
+			FilePath:       lesson.records[0].FilePath,
+			Sheet:          lesson.records[0].Sheet,
+			TargetFilePath: lesson.records[0].FilePath,
+			TargetSheet:    lesson.records[0].Sheet,
+		}
+
+		var (
+			fingerprint, hasFingerprint = otherFingerprintsByPath[[2]string{merged.FilePath, merged.Sheet}]
+			target                      *lessonFingerprint
+		)
+
+		if hasFingerprint {
+			target = mostSimilarLesson(fingerprint, storedFingerprints)
+		} else if found, exists := lessonsByFingerprint[lesson.fingerprint]; exists {
+			target = &lessonFingerprint{filePath: found.FilePath, sheet: found.Sheet}
+		}
+
+		if lessonsByPath[[2]string{merged.FilePath, merged.Sheet}] {
+			merged.Match = LessonMatchPath
+		} else if target != nil {
+			merged.Match = LessonMatchFingerprint
+			merged.TargetFilePath = target.filePath
+			merged.TargetSheet = target.sheet
+
+			for i := range lesson.records {
+				lesson.records[i].FilePath = target.filePath
+				lesson.records[i].Sheet = target.sheet
+			}
+		}
+
+		merged.Added, merged.Merged, err = s.importProgress(ctx, tx, lesson.records, policy)
+
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("merging of %q, %q: %w", merged.FilePath, merged.Sheet, err), tx.Rollback())
+		}
+
+		res = append(res, merged)
+	}
+
+	if dryRun {
+		return res, tx.Rollback()
+	}
+
+	return res, tx.Commit()
+}
+
+// Opens a copy of the storage file made by copyFilePath, the file itself is opened read only.
+func openCopy(ctx context.Context, filePath, copyFilePath string) (*File, error) {
+	db, err := sql.Open("sqlite3", "file:"+filePath+"?mode=ro")
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, "VACUUM INTO ?", copyFilePath)
+
+	err = errors.Join(err, db.Close())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Open(ctx, copyFilePath)
+}
+
+// Returns stored fingerprints of lessons of the profile, the most recently used lesson first.
+func (s *File) lessonFingerprints(ctx context.Context) ([]lessonFingerprint, error) {
+	requestText := `
+		SELECT FILE_PATH, FILE_SHEET, FINGERPRINT
+		FROM EXCEL_LESSONS
+		WHERE PROFILE = ? AND FINGERPRINT != ''
+		ORDER BY DATE_UTC DESC
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res               = []lessonFingerprint{}
+		lesson            lessonFingerprint
+		storedFingerprint string
+	)
+
+	for query.Next() {
+		err = query.Scan(&lesson.filePath, &lesson.sheet, &storedFingerprint)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lesson.fingerprint, err = parseFingerprint(storedFingerprint)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, lesson)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
+
+// Returns the lesson whose content is the most similar to the fingerprint,
+// or nil if no lesson has Similarity not less than MIN_RELINK_SIMILARITY.
+// The first lesson is chosen among equally similar ones.
+func mostSimilarLesson(fingerprint Fingerprint, lessons []lessonFingerprint) *lessonFingerprint {
+	var (
+		res            *lessonFingerprint
+		bestSimilarity float64
+	)
+
+	for i := range lessons {
+		similarity := fingerprint.Similarity(lessons[i].fingerprint)
+
+		if similarity >= MIN_RELINK_SIMILARITY && (res == nil || similarity > bestSimilarity) {
+			res = &lessons[i]
+			bestSimilarity = similarity
+		}
+	}
+
+	return res
+}
+
+// Splits records ordered by lesson (see ExportProgress) into lessons.
+func groupByLesson(records []ProgressRecord) []lessonProgress {
+	res := []lessonProgress{}
+
+	for begin := 0; begin < len(records); {
+		end := begin + 1
+
+		for end < len(records) && records[end].FilePath == records[begin].FilePath && records[end].Sheet == records[begin].Sheet {
+			end++
+		}
+
+		res = append(
+			res,
+			lessonProgress{
+				records:     records[begin:end],
+				fingerprint: progressFingerprint(records[begin:end]),
+			},
+		)
+
+		begin = end
+	}
+
+	return res
+}
+
+// Returns the hash of the set of phrases of the records.
+func progressFingerprint(records []ProgressRecord) string {
+	keys := make([]string, len(records))
+
+	for i := range records {
+		keys[i] = fmt.Sprintf("%s\x00%d", records[i].Phrase, records[i].Occurrence)
+	}
+
+	slices.Sort(keys)
+
+	hash := sha256.New()
+
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{'\n'})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
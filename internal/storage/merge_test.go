@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestMerge(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		otherPath = filepath.Join(dir, "other")
+	)
+
+	file, err := Open(t.Context(), filepath.Join(dir, "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	other, err := Open(t.Context(), otherPath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows, otherRows []string
+
+	for i := range 10 {
+		rows = append(rows, fmt.Sprint("row ", i))
+		otherRows = append(otherRows, fmt.Sprint("other row ", i))
+	}
+
+	for _, lesson := range []struct {
+		file        *File
+		path        string
+		sheet       string
+		progress    map[app.PhraseKey]advanced.PhraseLearningStatistics
+		fingerprint Fingerprint
+	}{
+		{file, "/home/words.xlsx", "Unit 1", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "car"}: {CountGuessedOOS: 1}}, nil},
+		{file, "/home/moved.xlsx", "Unit 2", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "dog"}: {CountFailedOOS: 1}}, nil},
+		{file, "/home/renamed.xlsx", "Unit 4", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "fox"}: {CountFailedOOS: 1}}, NewFingerprint(rows)},
+		{other, "/home/words.xlsx", "Unit 1", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "car"}: {CountGuessedOOS: 2}, {Phrase: "cat"}: {CountAnsweredTM: 1}}, nil},
+		{other, "/work/words.xlsx", "Unit 2", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "dog"}: {CountFailedOOS: 2}}, nil},
+		{other, "/work/words.xlsx", "Unit 3", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "cow"}: {CountFailedTM: 1}}, nil},
+		//Content is mostly the same, though other phrases have progress.
+		{other, "/work/words.xlsx", "Unit 4", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "fox"}: {CountFailedOOS: 1}, {Phrase: "owl"}: {CountFailedOOS: 1}}, NewFingerprint(append(rows, "row 10"))},
+		//The same phrases have progress, though the content is another.
+		{other, "/work/words.xlsx", "Unit 5", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "dog"}: {CountFailedOOS: 1}}, NewFingerprint(otherRows)},
+	} {
+		err = lesson.file.SaveLessonProgress(t.Context(), lesson.path, lesson.sheet, lesson.progress)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if lesson.fingerprint != nil {
+			err = lesson.file.SaveFingerprint(t.Context(), lesson.path, lesson.sheet, lesson.fingerprint)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	other.Close()
+
+	otherContent, err := os.ReadFile(otherPath + FILE_EXTENTION)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []MergedLesson{
+		{"/home/words.xlsx", "Unit 1", "/home/words.xlsx", "Unit 1", LessonMatchPath, 1, 1},
+		{"/work/words.xlsx", "Unit 2", "/home/moved.xlsx", "Unit 2", LessonMatchFingerprint, 0, 1},
+		{"/work/words.xlsx", "Unit 3", "/work/words.xlsx", "Unit 3", LessonMatchNone, 1, 0},
+		{"/work/words.xlsx", "Unit 4", "/home/renamed.xlsx", "Unit 4", LessonMatchFingerprint, 1, 1},
+		{"/work/words.xlsx", "Unit 5", "/work/words.xlsx", "Unit 5", LessonMatchNone, 1, 0},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		merged, err := file.Merge(t.Context(), otherPath, ConflictSum, dryRun)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(merged) != len(expected) {
+			t.Fatal("unexpected report:", merged)
+		}
+
+		for i := range expected {
+			if merged[i] != expected[i] {
+				t.Fatalf("expected %+v, got %+v", expected[i], merged[i])
+			}
+		}
+
+		lessons, err := file.ListLessons(t.Context())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if dryRun && len(lessons) != 3 {
+			t.Fatal("dry run shouldn't change the storage:", lessons)
+		}
+	}
+
+	loaded, err := file.LoadLessonProgress(t.Context(), "/home/moved.xlsx", "Unit 2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded[app.PhraseKey{Phrase: "dog"}].CountFailedOOS != 3 {
+		t.Fatal("counters should be summed:", loaded)
+	}
+
+	content, err := os.ReadFile(otherPath + FILE_EXTENTION)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(content, otherContent) {
+		t.Fatal("the other file shouldn't be changed")
+	}
+
+	missingPath := filepath.Join(dir, "missing")
+
+	_, err = file.Merge(t.Context(), missingPath, ConflictSum, true)
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("fs.ErrNotExist expected, got:", err)
+	}
+
+	_, err = os.Stat(missingPath + FILE_EXTENTION)
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("the missing file shouldn't be created:", err)
+	}
+}
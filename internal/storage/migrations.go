@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Changes the schema of database from the version i to the version i + 1,
+// where i is the index of migration in migrations slice.
+type migration func(ctx context.Context, tx *sql.Tx) error
+
+// Ordered list of all the changes of database schema. The version of the schema
+// (PRAGMA user_version) is the count of applied migrations.
+//
+// Never change migrations which were released, append new ones instead.
+// First migrations use "IF NOT EXISTS" because they were applied without versioning
+// earlier (such databases have version 0).
+var migrations = []migration{
+	//1: initial schema.
+	execMigration(`
+		CREATE TABLE IF NOT EXISTS EXCEL_LESSONS
+		(
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			DATE_UTC TEXT NOT NULL,
+			FILE_PATH TEXT NOT NULL,
+			FILE_SHEET TEXT NOT NULL,
+			MODE INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS LESSONS_PROGRESS
+		(
+			EXCEL_LESSON INTEGER NOT NULL,
+			PHRASE TEXT NOT NULL,
+			COUNT_GUESSED_OOS INTEGER NOT NULL,
+			COUNT_FAILED_OOS INTEGER NOT NULL,
+			COUNT_ANSWERED_TM INTEGER NOT NULL,
+			COUNT_FAILED_TM INTEGER NOT NULL,
+			COUNT_GUESSED_OOS_INVERTED INTEGER NOT NULL,
+			COUNT_FAILED_OOS_INVERTED INTEGER NOT NULL,
+			COUNT_ANSWERED_TM_INVERTED INTEGER NOT NULL,
+			COUNT_FAILED_TM_INVERTED INTEGER NOT NULL,
+			FOREIGN KEY (EXCEL_LESSON) REFERENCES EXCEL_LESSONS(ID) ON DELETE CASCADE
+		);
+	`),
+
+	//2: schedule of spaced repetition.
+	execMigration(`
+		CREATE TABLE IF NOT EXISTS LESSONS_SCHEDULE
+		(
+			EXCEL_LESSON INTEGER NOT NULL,
+			PHRASE TEXT NOT NULL,
+			STABILITY REAL NOT NULL,
+			DIFFICULTY REAL NOT NULL,
+			DUE_UTC TEXT NOT NULL,
+			LAST_REVIEW_UTC TEXT NOT NULL,
+			REVIEWS INTEGER NOT NULL,
+			LAPSES INTEGER NOT NULL,
+			FOREIGN KEY (EXCEL_LESSON) REFERENCES EXCEL_LESSONS(ID) ON DELETE CASCADE
+		);
+	`),
+
+	//3: log of answers.
+	execMigration(`
+		CREATE TABLE IF NOT EXISTS REVIEWS
+		(
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			EXCEL_LESSON INTEGER NOT NULL,
+			PHRASE TEXT NOT NULL,
+			KIND_OF_TASK INTEGER NOT NULL,
+			INVERTED INTEGER NOT NULL,
+			CORRECT INTEGER NOT NULL,
+			ANSWER TEXT NOT NULL,
+			RIGHT_ANSWER_REQUESTED INTEGER NOT NULL,
+			DATE_UTC TEXT NOT NULL,
+			FOREIGN KEY (EXCEL_LESSON) REFERENCES EXCEL_LESSONS(ID) ON DELETE CASCADE
+		);
+	`),
+
+	//4: unique progress of a phrase in a lesson (needed for upserts).
+	execMigration(`
+		DELETE FROM LESSONS_PROGRESS
+		WHERE ROWID NOT IN (
+			SELECT MAX(ROWID)
+			FROM LESSONS_PROGRESS
+			GROUP BY EXCEL_LESSON, PHRASE
+		);
+
+		CREATE UNIQUE INDEX LESSONS_PROGRESS_PHRASE
+		ON LESSONS_PROGRESS (EXCEL_LESSON, PHRASE);
+	`),
+
+	//5: almost correct manual translations.
+	execMigration(`
+		ALTER TABLE LESSONS_PROGRESS ADD COLUMN COUNT_ALMOST_TM INTEGER NOT NULL DEFAULT 0;
+
+		ALTER TABLE LESSONS_PROGRESS ADD COLUMN COUNT_ALMOST_TM_INVERTED INTEGER NOT NULL DEFAULT 0;
+
+		ALTER TABLE REVIEWS ADD COLUMN ALMOST_CORRECT INTEGER NOT NULL DEFAULT 0;
+	`),
+
+	//6: seeds of lessons' sessions (for replaying).
+	execMigration(`
+		CREATE TABLE SESSIONS
+		(
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			EXCEL_LESSON INTEGER NOT NULL,
+			MODE INTEGER NOT NULL,
+			SEED INTEGER NOT NULL,
+			DATE_UTC TEXT NOT NULL,
+			FOREIGN KEY (EXCEL_LESSON) REFERENCES EXCEL_LESSONS(ID) ON DELETE CASCADE
+		);
+	`),
+
+	//7: confusions of phrases in choice tasks.
+	execMigration(`
+		CREATE TABLE CONFUSIONS
+		(
+			EXCEL_LESSON INTEGER NOT NULL,
+			PHRASE TEXT NOT NULL,
+			CONFUSED_WITH TEXT NOT NULL,
+			COUNT INTEGER NOT NULL,
+			LAST_UTC TEXT NOT NULL,
+			FOREIGN KEY (EXCEL_LESSON) REFERENCES EXCEL_LESSONS(ID) ON DELETE CASCADE
+		);
+
+		CREATE UNIQUE INDEX CONFUSIONS_PHRASES
+		ON CONFUSIONS (EXCEL_LESSON, PHRASE, CONFUSED_WITH);
+	`),
+
+	//8: separate progress of duplicate phrases (see app.PhraseKey).
+	execMigration(`
+		ALTER TABLE LESSONS_PROGRESS ADD COLUMN OCCURRENCE INTEGER NOT NULL DEFAULT 0;
+
+		DROP INDEX LESSONS_PROGRESS_PHRASE;
+
+		CREATE UNIQUE INDEX LESSONS_PROGRESS_PHRASE
+		ON LESSONS_PROGRESS (EXCEL_LESSON, PHRASE, OCCURRENCE);
+
+		ALTER TABLE LESSONS_SCHEDULE ADD COLUMN OCCURRENCE INTEGER NOT NULL DEFAULT 0;
+	`),
+
+	//9: fingerprints of content of lessons (see Fingerprint).
+	execMigration(`
+		ALTER TABLE EXCEL_LESSONS ADD COLUMN FINGERPRINT TEXT NOT NULL DEFAULT '';
+	`),
+
+	//10: statistics of phrases shared by all the lessons (see advanced.KnowledgeKey).
+	execMigration(`
+		CREATE TABLE PHRASE_KNOWLEDGE
+		(
+			PHRASE TEXT NOT NULL,
+			TRANSLATION TEXT NOT NULL,
+			COUNT_GUESSED_OOS INTEGER NOT NULL,
+			COUNT_FAILED_OOS INTEGER NOT NULL,
+			COUNT_ANSWERED_TM INTEGER NOT NULL,
+			COUNT_FAILED_TM INTEGER NOT NULL,
+			COUNT_GUESSED_OOS_INVERTED INTEGER NOT NULL,
+			COUNT_FAILED_OOS_INVERTED INTEGER NOT NULL,
+			COUNT_ANSWERED_TM_INVERTED INTEGER NOT NULL,
+			COUNT_FAILED_TM_INVERTED INTEGER NOT NULL,
+			COUNT_ALMOST_TM INTEGER NOT NULL,
+			COUNT_ALMOST_TM_INVERTED INTEGER NOT NULL,
+			DATE_UTC TEXT NOT NULL
+		);
+
+		CREATE UNIQUE INDEX PHRASE_KNOWLEDGE_PHRASE
+		ON PHRASE_KNOWLEDGE (PHRASE, TRANSLATION);
+	`),
+
+	//11: profiles of learners, existing lessons and knowledge belong to the default one.
+	execMigration(`
+		CREATE TABLE PROFILES
+		(
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			NAME TEXT NOT NULL UNIQUE,
+			DATE_UTC TEXT NOT NULL
+		);
+
+		INSERT INTO PROFILES (ID, NAME, DATE_UTC) VALUES
+		(1, 'default', STRFTIME('%Y-%m-%d %H:%M:%f', 'now'));
+
+		ALTER TABLE EXCEL_LESSONS ADD COLUMN PROFILE INTEGER NOT NULL DEFAULT 1;
+
+		ALTER TABLE PHRASE_KNOWLEDGE ADD COLUMN PROFILE INTEGER NOT NULL DEFAULT 1;
+
+		DROP INDEX PHRASE_KNOWLEDGE_PHRASE;
+
+		CREATE UNIQUE INDEX PHRASE_KNOWLEDGE_PHRASE
+		ON PHRASE_KNOWLEDGE (PROFILE, PHRASE, TRANSLATION);
+	`),
+
+	//12: starting state of sessions.
+	execMigration(`
+		ALTER TABLE SESSIONS ADD COLUMN PROGRESS_RECOVERED INTEGER NOT NULL DEFAULT 0;
+
+		ALTER TABLE SESSIONS ADD COLUMN STATE_HASH TEXT NOT NULL DEFAULT '';
+	`),
+
+	//13: settings of comparison of answers per lesson (IGNORABLE_WORDS is NULL until they are stored).
+	execMigration(`
+		ALTER TABLE EXCEL_LESSONS ADD COLUMN PHRASE_LANGUAGE TEXT NOT NULL DEFAULT '';
+
+		ALTER TABLE EXCEL_LESSONS ADD COLUMN TRANSLATION_LANGUAGE TEXT NOT NULL DEFAULT '';
+
+		ALTER TABLE EXCEL_LESSONS ADD COLUMN IGNORE_DIACRITICS INTEGER NOT NULL DEFAULT 0;
+
+		ALTER TABLE EXCEL_LESSONS ADD COLUMN IGNORABLE_WORDS TEXT;
+	`),
+}
+
+func execMigration(requestText string) migration {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, requestText)
+
+		return err
+	}
+}
+
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+
+	err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+
+	return version, err
+}
+
+// Applies all the migrations which haven't been applied yet.
+// Each migration is applied in a separate transaction together with the change of schema version,
+// so the database always has one of the known versions.
+func migrate(ctx context.Context, db *sql.DB) error {
+	version, err := schemaVersion(ctx, db)
+
+	if err != nil {
+		return err
+	}
+
+	if version > len(migrations) {
+		return ErrUnsupportedVersion
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+
+		if err != nil {
+			return err
+		}
+
+		err = migrations[version](ctx, tx)
+
+		if err != nil {
+			return errors.Join(fmt.Errorf("migration to version %d: %w", version+1, err), tx.Rollback())
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1))
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+
+		err = tx.Commit()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+const version1Data = `
+	INSERT INTO EXCEL_LESSONS (DATE_UTC, FILE_PATH, FILE_SHEET, MODE) VALUES
+	('2025-01-02 03:04:05.000', '/home/user/words.xlsx', 'Unit 1', 0);
+
+	INSERT INTO LESSONS_PROGRESS VALUES
+	(1, 'car', 3, 1, 2, 0, 4, 0, 1, 1);
+`
+
+// Creates a database file with schema of version 1 (as it was created by
+// the first releases of the program) and returns its' path.
+func createVersion1Database(t *testing.T, userVersion int) string {
+	filePath := filepath.Join(t.TempDir(), "storage"+FILE_EXTENTION)
+
+	db, err := sql.Open("sqlite3", filePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	tx, err := db.BeginTx(t.Context(), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = migrations[0](t.Context(), tx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(version1Data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(fmt.Sprintf("PRAGMA user_version = %d", userVersion))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+func testUpgradeOfVersion1Database(t *testing.T, userVersion int) {
+	filePath := createVersion1Database(t, userVersion)
+
+	file, err := Open(t.Context(), filePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	version, err := schemaVersion(t.Context(), file.db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != len(migrations) {
+		t.Fatalf("schema version is %d, expected %d", version, len(migrations))
+	}
+
+	statisticsByPhrase, err := file.LoadLessonProgress(t.Context(), "/home/user/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := advanced.PhraseLearningStatistics{
+		CountGuessedOOS:         3,
+		CountFailedOOS:          1,
+		CountAnsweredTM:         2,
+		CountGuessedOOSInverted: 4,
+		CountAnsweredTMInverted: 1,
+		CountFailedTMInverted:   1,
+	}
+
+	if statisticsByPhrase[app.PhraseKey{Phrase: "car"}] != expected {
+		t.Fatal("progress wasn't kept after upgrade:", statisticsByPhrase)
+	}
+
+	err = file.SaveLessonSchedule(
+		t.Context(),
+		"/home/user/words.xlsx",
+		"Unit 1",
+		map[app.PhraseKey]advanced.PhraseSchedule{{Phrase: "car"}: {Stability: 1, Reviews: 1}},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeOfVersion1Database(t *testing.T) {
+	testUpgradeOfVersion1Database(t, 1)
+}
+
+func TestUpgradeOfUnversionedDatabase(t *testing.T) {
+	testUpgradeOfVersion1Database(t, 0)
+}
+
+func TestReopening(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "storage")
+
+	for range 2 {
+		file, err := Open(t.Context(), filePath)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = file.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	filePath := createVersion1Database(t, 1)
+
+	errMigrationFailed := errors.New("migration failed")
+
+	originalMigrations := migrations
+
+	defer func() {
+		migrations = originalMigrations
+	}()
+
+	migrations = append(
+		migrations[:1:1],
+		func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "CREATE TABLE SHOULD_NOT_EXIST (ID INTEGER)")
+
+			if err != nil {
+				return err
+			}
+
+			return errMigrationFailed
+		},
+	)
+
+	_, err := Open(t.Context(), filePath)
+
+	if !errors.Is(err, errMigrationFailed) {
+		t.Fatal("error of migration should be returned, got:", err)
+	}
+
+	db, err := sql.Open("sqlite3", filePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	version, err := schemaVersion(t.Context(), db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 1 {
+		t.Fatal("schema version shouldn't be changed, got", version)
+	}
+
+	var tablesCount int
+
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'SHOULD_NOT_EXIST'").Scan(&tablesCount)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tablesCount != 0 {
+		t.Fatal("changes of failed migration should be rolled back")
+	}
+}
+
+func TestNewerVersionIsRejected(t *testing.T) {
+	filePath := createVersion1Database(t, len(migrations)+1)
+
+	_, err := Open(t.Context(), filePath)
+
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatal("ErrUnsupportedVersion should be returned, got:", err)
+	}
+}
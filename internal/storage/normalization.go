@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"vocabulary/internal/app/advanced"
+)
+
+// Stores settings of comparison of answers of the lesson.
+// The lesson should be saved earlier (by SaveLastOpen or SaveSession call).
+func (s *File) SaveNormalization(ctx context.Context, excelFilePath, sheet string, normalization advanced.Normalization) error {
+	//Lists of ignorable words are encoded as JSON: an absent language and an empty list of a language differ.
+	ignorableWords, err := json.Marshal(normalization.IgnorableLeadingWords)
+
+	if err != nil {
+		return err
+	}
+
+	requestText := `
+		UPDATE EXCEL_LESSONS
+		SET PHRASE_LANGUAGE = ?, TRANSLATION_LANGUAGE = ?, IGNORE_DIACRITICS = ?, IGNORABLE_WORDS = ?
+		WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+	`
+
+	res, err := s.db.ExecContext(
+		ctx,
+		requestText,
+		normalization.PhraseLanguage,
+		normalization.TranslationLanguage,
+		normalization.IgnoreDiacritics,
+		string(ignorableWords),
+		s.profileID,
+		excelFilePath,
+		sheet,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if updated <= 0 {
+		return ErrWasNotSaved
+	}
+
+	return nil
+}
+
+// Returns settings of comparison of answers of the lesson.
+// Returns ErrWasNotSaved if the lesson or its' settings weren't stored.
+func (s *File) LoadNormalization(ctx context.Context, excelFilePath, sheet string) (advanced.Normalization, error) {
+	requestText := `
+		SELECT PHRASE_LANGUAGE, TRANSLATION_LANGUAGE, IGNORE_DIACRITICS, IGNORABLE_WORDS
+		FROM EXCEL_LESSONS
+		WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+	`
+
+	row := s.db.QueryRowContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	var (
+		res            advanced.Normalization
+		ignorableWords sql.NullString
+	)
+
+	err := row.Scan(&res.PhraseLanguage, &res.TranslationLanguage, &res.IgnoreDiacritics, &ignorableWords)
+
+	if errors.Is(err, sql.ErrNoRows) || err == nil && !ignorableWords.Valid {
+		return advanced.Normalization{}, ErrWasNotSaved
+	}
+
+	if err != nil {
+		return advanced.Normalization{}, err
+	}
+
+	err = json.Unmarshal([]byte(ignorableWords.String), &res.IgnorableLeadingWords)
+
+	if err != nil {
+		return advanced.Normalization{}, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestNormalization(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	normalization := advanced.Normalization{
+		IgnoreDiacritics:      true,
+		PhraseLanguage:        "de",
+		TranslationLanguage:   "en",
+		IgnorableLeadingWords: map[string][]string{"en": {"the", "to"}, "de": {}},
+	}
+
+	err = file.SaveNormalization(t.Context(), "words.xlsx", "Unit 1", normalization)
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatalf("settings of an absent lesson were saved: %v", err)
+	}
+
+	err = file.SaveLastOpen(t.Context(), "words.xlsx", "Unit 1", app.LessonModeLern)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = file.LoadNormalization(t.Context(), "words.xlsx", "Unit 1")
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatalf("settings which weren't saved were loaded: %v", err)
+	}
+
+	err = file.SaveNormalization(t.Context(), "words.xlsx", "Unit 1", normalization)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := file.LoadNormalization(t.Context(), "words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded, normalization) {
+		t.Fatalf("loaded %+v instead of %+v", loaded, normalization)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Returns names of all the profiles, the most recently selected first.
+func (s *File) Profiles(ctx context.Context) ([]string, error) {
+	requestText := `
+		SELECT NAME
+		FROM PROFILES
+		ORDER BY DATE_UTC DESC
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res  = []string{}
+		name string
+	)
+
+	for query.Next() {
+		err = query.Scan(&name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, name)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
+
+// Returns the name of the selected profile.
+func (s *File) Profile() string {
+	return s.profileName
+}
+
+// Makes the profile selected (it is added if there is no profile with such a name),
+// further calls read and write lessons of this profile only.
+func (s *File) SelectProfile(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return ErrEmptyProfileName
+	}
+
+	requestText := `
+		INSERT INTO PROFILES (NAME, DATE_UTC) VALUES
+		(?, ?)
+		ON CONFLICT (NAME) DO UPDATE SET
+			DATE_UTC = excluded.DATE_UTC
+		RETURNING ID
+	`
+
+	var profileID int64
+
+	err := s.db.QueryRowContext(ctx, requestText, name, time.Now().UTC().Format(SQLITE_TIME_FORMAT)).Scan(&profileID)
+
+	if err != nil {
+		return err
+	}
+
+	s.profileID = profileID
+	s.profileName = name
+
+	return nil
+}
+
+func (s *File) selectLastProfile(ctx context.Context) error {
+	requestText := `
+		SELECT ID, NAME
+		FROM PROFILES
+		ORDER BY DATE_UTC DESC
+		LIMIT 1
+	`
+
+	return s.db.QueryRowContext(ctx, requestText).Scan(&s.profileID, &s.profileName)
+}
+
+// Unlike SelectProfile, doesn't add the profile and doesn't change the order of profiles.
+// Returns ErrWasNotSaved if there is no profile with such a name.
+func (s *File) UseProfile(ctx context.Context, name string) error {
+	requestText := `
+		SELECT ID, NAME
+		FROM PROFILES
+		WHERE NAME = ?
+	`
+
+	err := s.db.QueryRowContext(ctx, requestText, name).Scan(&s.profileID, &s.profileName)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWasNotSaved
+	}
+
+	return err
+}
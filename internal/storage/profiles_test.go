@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestProfiles(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "storage")
+
+	file, err := Open(t.Context(), filePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.Profile() != "default" {
+		t.Fatal("default profile should be selected in a new storage, got:", file.Profile())
+	}
+
+	err = file.SaveLessonProgress(
+		t.Context(),
+		"/home/words.xlsx",
+		"Unit 1",
+		map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "car"}: {CountGuessedOOS: 1}},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.SelectProfile(t.Context(), " ")
+
+	if !errors.Is(err, ErrEmptyProfileName) {
+		t.Fatal("ErrEmptyProfileName should be returned, got:", err)
+	}
+
+	err = file.SelectProfile(t.Context(), "Anna")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = file.LoadLessonProgress(t.Context(), "/home/words.xlsx", "Unit 1")
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatal("progress of another profile shouldn't be loaded, got:", err)
+	}
+
+	_, _, _, err = file.LoadLastOpen(t.Context())
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatal("the last lesson of another profile shouldn't be loaded, got:", err)
+	}
+
+	err = file.SaveLastOpen(t.Context(), "/home/words.xlsx", "Unit 2", app.LessonModeLern)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+
+	//The last selected profile is selected after reopening.
+	file, err = Open(t.Context(), filePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	profiles, err := file.Profiles(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if file.Profile() != "Anna" || !slices.Equal(profiles, []string{"Anna", "default"}) {
+		t.Fatal("unexpected profiles:", file.Profile(), profiles)
+	}
+
+	_, sheet, _, err := file.LoadLastOpen(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sheet != "Unit 2" {
+		t.Fatal("unexpected last open sheet:", sheet)
+	}
+
+	err = file.SelectProfile(t.Context(), "default")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := file.LoadLessonProgress(t.Context(), "/home/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded[app.PhraseKey{Phrase: "car"}].CountGuessedOOS != 1 {
+		t.Fatal("unexpected progress:", loaded)
+	}
+}
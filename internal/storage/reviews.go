@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"time"
+	"vocabulary/internal/app/advanced"
+)
+
+// Appends a record to the log of answers. The lesson should be saved earlier
+// (by SaveLastOpen or SaveLessonProgress call), otherwise ErrWasNotSaved is returned.
+func (s *File) AddReview(ctx context.Context, excelFilePath, sheet string, review advanced.Review) error {
+	requestText := `
+		INSERT INTO REVIEWS
+		(
+			EXCEL_LESSON,
+			PHRASE,
+			KIND_OF_TASK,
+			INVERTED,
+			CORRECT,
+			ALMOST_CORRECT,
+			ANSWER,
+			RIGHT_ANSWER_REQUESTED,
+			DATE_UTC
+		)
+		SELECT ID, ?, ?, ?, ?, ?, ?, ?, ?
+		FROM EXCEL_LESSONS
+		WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+	`
+
+	requestRes, err := s.db.ExecContext(
+		ctx,
+		requestText,
+		review.Phrase,
+		review.KindOfTask,
+		review.Inverted,
+		review.Correct,
+		review.AlmostCorrect,
+		review.Answer,
+		review.RightAnswerRequested,
+		review.TimeUTC.UTC().Format(SQLITE_TIME_FORMAT),
+		s.profileID,
+		excelFilePath,
+		sheet,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := requestRes.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return ErrWasNotSaved
+	}
+
+	return nil
+}
+
+// Returns all the logged answers of the lesson in the order of their addition.
+func (s *File) LoadReviews(ctx context.Context, excelFilePath, sheet string) ([]advanced.Review, error) {
+	requestText := `
+		SELECT
+			REVIEWS.PHRASE,
+			REVIEWS.KIND_OF_TASK,
+			REVIEWS.INVERTED,
+			REVIEWS.CORRECT,
+			REVIEWS.ALMOST_CORRECT,
+			REVIEWS.ANSWER,
+			REVIEWS.RIGHT_ANSWER_REQUESTED,
+			REVIEWS.DATE_UTC
+		FROM EXCEL_LESSONS JOIN REVIEWS
+			ON EXCEL_LESSONS.ID = REVIEWS.EXCEL_LESSON
+		WHERE
+			EXCEL_LESSONS.PROFILE = ? AND EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
+		ORDER BY REVIEWS.ID
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res     = []advanced.Review{}
+		review  advanced.Review
+		dateUTC string
+	)
+
+	for query.Next() {
+		err = query.Scan(
+			&review.Phrase,
+			&review.KindOfTask,
+			&review.Inverted,
+			&review.Correct,
+			&review.AlmostCorrect,
+			&review.Answer,
+			&review.RightAnswerRequested,
+			&dateUTC,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		review.TimeUTC, err = time.Parse(SQLITE_TIME_FORMAT, dateUTC)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, review)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestReviews(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	reviewTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	reviews := []advanced.Review{
+		{Phrase: "car", KindOfTask: advanced.KindOfTaskChooseOneOption, Correct: true, Answer: "машина", TimeUTC: reviewTime},
+		{Phrase: "receive", KindOfTask: advanced.KindOfTaskTranslateManually, Inverted: true, AlmostCorrect: true, Answer: "recieve", TimeUTC: reviewTime},
+		{Phrase: "receive", KindOfTask: advanced.KindOfTaskTranslateManually, RightAnswerRequested: true, TimeUTC: reviewTime.Add(time.Second)},
+	}
+
+	err = file.AddReview(t.Context(), "/home/words.xlsx", "Unit 1", reviews[0])
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatal("answers of unsaved lessons shouldn't be logged, got:", err)
+	}
+
+	err = file.SaveLastOpen(t.Context(), "/home/words.xlsx", "Unit 1", app.LessonModeLern)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, review := range reviews {
+		err = file.AddReview(t.Context(), "/home/words.xlsx", "Unit 1", review)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := file.LoadReviews(t.Context(), "/home/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != len(reviews) {
+		t.Fatal("unexpected reviews:", loaded)
+	}
+
+	for i := range reviews {
+		if loaded[i] != reviews[i] {
+			t.Fatalf("expected %+v, got %+v", reviews[i], loaded[i])
+		}
+	}
+
+	//The log is kept when the lesson is relinked or removed as outdated.
+	err = file.SaveLessonProgress(t.Context(), "/home/moved.xlsx", "Unit 1", map[app.PhraseKey]advanced.PhraseLearningStatistics{{Phrase: "car"}: {CountGuessedOOS: 1}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.Relink(t.Context(), "/home/moved.xlsx", "Unit 1", "/home/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.EraseOutdatedData(t.Context(), 0, time.Now().Add(time.Hour))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err = file.LoadReviews(t.Context(), "/home/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != len(reviews) {
+		t.Fatal("the log of answers shouldn't be removed:", loaded)
+	}
+
+	problems, err := file.CheckIntegrity(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) > 0 {
+		t.Fatal("unexpected problems of integrity:", problems)
+	}
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func (s *File) SaveLessonSchedule(
+	ctx context.Context,
+	excelFilePath string,
+	sheet string,
+	scheduleByPhrase map[app.PhraseKey]advanced.PhraseSchedule,
+) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	requestText := `
+		DELETE FROM LESSONS_SCHEDULE
+		WHERE EXCEL_LESSON IN (
+			SELECT ID
+			FROM EXCEL_LESSONS
+			WHERE PROFILE = ? AND FILE_PATH = ? AND FILE_SHEET = ?
+		)
+	`
+
+	_, err = tx.ExecContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	err = s.updateExcelLessonDateOrAddExcelLesson(ctx, tx, excelFilePath, sheet, app.LessonModeSpacedRepetition)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	lessonID, err := s.getExcelLessonID(ctx, tx, excelFilePath, sheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	requestText = `
+		INSERT INTO LESSONS_SCHEDULE
+		(
+			EXCEL_LESSON,
+			PHRASE,
+			OCCURRENCE,
+			STABILITY,
+			DIFFICULTY,
+			DUE_UTC,
+			LAST_REVIEW_UTC,
+			REVIEWS,
+			LAPSES
+		)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	preparedRequest, err := tx.PrepareContext(ctx, requestText)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	for key, schedule := range scheduleByPhrase {
+		_, err = preparedRequest.ExecContext(
+			ctx,
+			lessonID,
+			key.Phrase,
+			key.Occurrence,
+			schedule.Stability,
+			schedule.Difficulty,
+			schedule.Due.UTC().Format(SQLITE_TIME_FORMAT),
+			schedule.LastReview.UTC().Format(SQLITE_TIME_FORMAT),
+			schedule.Reviews,
+			schedule.Lapses,
+		)
+
+		if err != nil {
+			return errors.Join(err, tx.Rollback())
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *File) LoadLessonSchedule(
+	ctx context.Context,
+	excelFilePath,
+	sheet string,
+) (
+	scheduleByPhrase map[app.PhraseKey]advanced.PhraseSchedule,
+	err error,
+) {
+	requestText := `
+		SELECT
+			LESSONS_SCHEDULE.PHRASE,
+			LESSONS_SCHEDULE.OCCURRENCE,
+			LESSONS_SCHEDULE.STABILITY,
+			LESSONS_SCHEDULE.DIFFICULTY,
+			LESSONS_SCHEDULE.DUE_UTC,
+			LESSONS_SCHEDULE.LAST_REVIEW_UTC,
+			LESSONS_SCHEDULE.REVIEWS,
+			LESSONS_SCHEDULE.LAPSES
+		FROM EXCEL_LESSONS JOIN LESSONS_SCHEDULE
+			ON EXCEL_LESSONS.ID = LESSONS_SCHEDULE.EXCEL_LESSON
+		WHERE
+			EXCEL_LESSONS.PROFILE = ? AND EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res                 = map[app.PhraseKey]advanced.PhraseSchedule{}
+		schedule            advanced.PhraseSchedule
+		key                 app.PhraseKey
+		due, lastReview     string
+		dueTime, reviewTime time.Time
+	)
+
+	for query.Next() {
+		err = query.Scan(
+			&key.Phrase,
+			&key.Occurrence,
+			&schedule.Stability,
+			&schedule.Difficulty,
+			&due,
+			&lastReview,
+			&schedule.Reviews,
+			&schedule.Lapses,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		dueTime, err = time.Parse(SQLITE_TIME_FORMAT, due)
+
+		if err != nil {
+			return nil, err
+		}
+
+		reviewTime, err = time.Parse(SQLITE_TIME_FORMAT, lastReview)
+
+		if err != nil {
+			return nil, err
+		}
+
+		schedule.Due = dueTime
+		schedule.LastReview = reviewTime
+
+		res[key] = schedule
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	if len(res) <= 0 {
+		return nil, ErrWasNotSaved
+	}
+
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestLessonSchedule(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	_, err = file.LoadLessonSchedule(t.Context(), "file.xlsx", "sheet")
+
+	if !errors.Is(err, ErrWasNotSaved) {
+		t.Fatal("ErrWasNotSaved expected, got:", err)
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	schedule := map[app.PhraseKey]advanced.PhraseSchedule{
+		{Phrase: "car", Occurrence: 0}: {
+			Stability:  2.5,
+			Difficulty: 0.25,
+			Due:        now.Add(time.Hour * 60),
+			LastReview: now,
+			Reviews:    3,
+		},
+		{Phrase: "car", Occurrence: 1}: {
+			Stability:  0.2,
+			Difficulty: 0.45,
+			Due:        now.Add(time.Minute),
+			LastReview: now,
+			Reviews:    4,
+			Lapses:     1,
+		},
+	}
+
+	err = file.SaveLessonSchedule(t.Context(), "file.xlsx", "sheet", schedule)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//The second saving replaces the first one.
+	err = file.SaveLessonSchedule(t.Context(), "file.xlsx", "sheet", schedule)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := file.LoadLessonSchedule(t.Context(), "file.xlsx", "sheet")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded, schedule) {
+		t.Fatalf("expected %+v, got %+v", schedule, loaded)
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+	"vocabulary/internal/app"
+)
+
+// The beginning of a lesson. Tasks of a lesson depend not only on the seed, but on the state
+// of the lesson at the beginning too (statistics or schedule of phrases, confusions and so on).
+// A session can be replayed task-for-task by creation of the lesson with the same seed
+// and the same settings only if the hash of the starting state is the same.
+type Session struct {
+	Mode     app.LessonMode
+	Seed     int64
+	StartUTC time.Time
+
+	//Stored progress of the lesson was recovered.
+	ProgressRecovered bool
+
+	//Hash of the starting state of the lesson (empty for sessions logged by older versions).
+	StateHash string
+}
+
+// Saves the lesson as the last open one (see SaveLastOpen) and logs its' session.
+// StartUTC of the session is ignored, the current time is stored.
+func (s *File) SaveSession(ctx context.Context, excelFilePath, sheet string, session Session) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.updateExcelLessonDateOrAddExcelLesson(ctx, tx, excelFilePath, sheet, session.Mode)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	lessonID, err := s.getExcelLessonID(ctx, tx, excelFilePath, sheet)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	requestText := `
+		INSERT INTO SESSIONS (EXCEL_LESSON, MODE, SEED, DATE_UTC, PROGRESS_RECOVERED, STATE_HASH) VALUES
+		(?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = tx.ExecContext(
+		ctx,
+		requestText,
+		lessonID,
+		session.Mode,
+		session.Seed,
+		time.Now().UTC().Format(SQLITE_TIME_FORMAT),
+		session.ProgressRecovered,
+		session.StateHash,
+	)
+
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
+
+// Returns all the logged sessions of the lesson in the order of their beginning.
+func (s *File) LoadSessions(ctx context.Context, excelFilePath, sheet string) ([]Session, error) {
+	requestText := `
+		SELECT
+			SESSIONS.MODE,
+			SESSIONS.SEED,
+			SESSIONS.DATE_UTC,
+			SESSIONS.PROGRESS_RECOVERED,
+			SESSIONS.STATE_HASH
+		FROM EXCEL_LESSONS JOIN SESSIONS
+			ON EXCEL_LESSONS.ID = SESSIONS.EXCEL_LESSON
+		WHERE
+			EXCEL_LESSONS.PROFILE = ? AND EXCEL_LESSONS.FILE_PATH = ? AND EXCEL_LESSONS.FILE_SHEET = ?
+		ORDER BY SESSIONS.ID
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res     = []Session{}
+		session Session
+		dateUTC string
+	)
+
+	for query.Next() {
+		err = query.Scan(&session.Mode, &session.Seed, &dateUTC, &session.ProgressRecovered, &session.StateHash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		session.StartUTC, err = time.Parse(SQLITE_TIME_FORMAT, dateUTC)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, session)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"vocabulary/internal/app"
+)
+
+func TestSessions(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	before := time.Now().UTC().Add(-time.Second)
+
+	for _, session := range []Session{
+		{Mode: app.LessonModeLern, Seed: 42, ProgressRecovered: true, StateHash: "abc"},
+		{Mode: app.LessonModeSpacedRepetition, Seed: -7},
+	} {
+		err = file.SaveSession(t.Context(), "/home/words.xlsx", "Unit 1", session)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions, err := file.LoadSessions(t.Context(), "/home/words.xlsx", "Unit 1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 2 ||
+		sessions[0].Mode != app.LessonModeLern || sessions[0].Seed != 42 ||
+		!sessions[0].ProgressRecovered || sessions[0].StateHash != "abc" ||
+		sessions[1].Mode != app.LessonModeSpacedRepetition || sessions[1].Seed != -7 ||
+		sessions[1].ProgressRecovered || sessions[1].StateHash != "" {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+
+	if sessions[0].StartUTC.Before(before) || sessions[1].StartUTC.Before(sessions[0].StartUTC) {
+		t.Fatalf("unexpected start times: %+v", sessions)
+	}
+
+	_, _, mode, err := file.LoadLastOpen(t.Context())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode != app.LessonModeSpacedRepetition {
+		t.Fatal("the lesson should be saved as the last open one with the mode of the last session, got:", mode)
+	}
+
+	sessions, err = file.LoadSessions(t.Context(), "/home/words.xlsx", "Unit 2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 0 {
+		t.Fatal("sessions of another lesson shouldn't be loaded:", sessions)
+	}
+}
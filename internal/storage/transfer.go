@@ -0,0 +1,349 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+// Stored learning statistics of a phrase of a lesson, exported for
+// moving to another storage or for analysis.
+type ProgressRecord struct {
+	FilePath string
+	Sheet    string
+
+	//The last usage of the lesson.
+	LessonUTC time.Time
+
+	Phrase     string
+	Occurrence uint32
+
+	//The last logged answer for the phrase (zero if answers weren't logged).
+	//Only exported: it is calculated from the log of answers.
+	LastAnswerUTC time.Time
+
+	Statistics advanced.PhraseLearningStatistics
+}
+
+func (r *ProgressRecord) Key() app.PhraseKey {
+	return app.PhraseKey{Phrase: r.Phrase, Occurrence: r.Occurrence}
+}
+
+// Defines how imported statistics of a phrase are merged with stored ones.
+type ConflictPolicy byte
+
+const (
+	//Each counter is the biggest of stored and imported ones
+	//(the same progress isn't counted twice).
+	ConflictKeepMax ConflictPolicy = iota
+
+	//Counters are added (progress was made independently).
+	ConflictSum
+
+	//Imported statistics replace stored ones.
+	ConflictOverwrite
+)
+
+// Returns stored progress of lessons of the profile of the file and the sheet
+// (of all the files or sheets if the argument is empty).
+func (s *File) ExportProgress(ctx context.Context, excelFilePath, sheet string) ([]ProgressRecord, error) {
+	requestText := `
+		SELECT
+			EXCEL_LESSONS.FILE_PATH,
+			EXCEL_LESSONS.FILE_SHEET,
+			EXCEL_LESSONS.DATE_UTC,
+			LESSONS_PROGRESS.PHRASE,
+			LESSONS_PROGRESS.OCCURRENCE,
+			(
+				SELECT MAX(REVIEWS.DATE_UTC)
+				FROM REVIEWS
+				WHERE
+					REVIEWS.EXCEL_LESSON = EXCEL_LESSONS.ID AND REVIEWS.PHRASE = LESSONS_PROGRESS.PHRASE
+			),
+			LESSONS_PROGRESS.COUNT_GUESSED_OOS,
+			LESSONS_PROGRESS.COUNT_FAILED_OOS,
+			LESSONS_PROGRESS.COUNT_ANSWERED_TM,
+			LESSONS_PROGRESS.COUNT_FAILED_TM,
+			LESSONS_PROGRESS.COUNT_GUESSED_OOS_INVERTED,
+			LESSONS_PROGRESS.COUNT_FAILED_OOS_INVERTED,
+			LESSONS_PROGRESS.COUNT_ANSWERED_TM_INVERTED,
+			LESSONS_PROGRESS.COUNT_FAILED_TM_INVERTED,
+			LESSONS_PROGRESS.COUNT_ALMOST_TM,
+			LESSONS_PROGRESS.COUNT_ALMOST_TM_INVERTED
+		FROM EXCEL_LESSONS JOIN LESSONS_PROGRESS
+			ON EXCEL_LESSONS.ID = LESSONS_PROGRESS.EXCEL_LESSON
+		WHERE
+			EXCEL_LESSONS.PROFILE = ?
+			AND (? = '' OR EXCEL_LESSONS.FILE_PATH = ?) AND (? = '' OR EXCEL_LESSONS.FILE_SHEET = ?)
+		ORDER BY
+			EXCEL_LESSONS.FILE_PATH,
+			EXCEL_LESSONS.FILE_SHEET,
+			LESSONS_PROGRESS.PHRASE,
+			LESSONS_PROGRESS.OCCURRENCE
+	`
+
+	query, err := s.db.QueryContext(ctx, requestText, s.profileID, excelFilePath, excelFilePath, sheet, sheet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer query.Close()
+
+	var (
+		res           = []ProgressRecord{}
+		record        ProgressRecord
+		lessonUTC     string
+		lastAnswerUTC sql.NullString
+	)
+
+	for query.Next() {
+		stats := &record.Statistics
+
+		err = query.Scan(
+			&record.FilePath,
+			&record.Sheet,
+			&lessonUTC,
+			&record.Phrase,
+			&record.Occurrence,
+			&lastAnswerUTC,
+			&stats.CountGuessedOOS,
+			&stats.CountFailedOOS,
+			&stats.CountAnsweredTM,
+			&stats.CountFailedTM,
+			&stats.CountGuessedOOSInverted,
+			&stats.CountFailedOOSInverted,
+			&stats.CountAnsweredTMInverted,
+			&stats.CountFailedTMInverted,
+			&stats.CountAlmostTM,
+			&stats.CountAlmostTMInverted,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		record.LessonUTC, err = time.Parse(SQLITE_TIME_FORMAT, lessonUTC)
+
+		if err != nil {
+			return nil, err
+		}
+
+		record.LastAnswerUTC = time.Time{}
+
+		if lastAnswerUTC.Valid {
+			record.LastAnswerUTC, err = time.Parse(SQLITE_TIME_FORMAT, lastAnswerUTC.String)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, record)
+	}
+
+	if query.Err() != nil {
+		return nil, query.Err()
+	}
+
+	return res, nil
+}
+
+// Merges the records into stored progress: statistics of phrases are matched by lesson
+// (the file and the sheet) and phrase, conflicts are resolved by the policy.
+// Unknown lessons are added. Returns counts of added and merged phrases.
+func (s *File) ImportProgress(ctx context.Context, records []ProgressRecord, policy ConflictPolicy) (added, merged int, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	added, merged, err = s.importProgress(ctx, tx, records, policy)
+
+	if err != nil {
+		return 0, 0, errors.Join(err, tx.Rollback())
+	}
+
+	return added, merged, tx.Commit()
+}
+
+func (s *File) importProgress(ctx context.Context, tx *sql.Tx, records []ProgressRecord, policy ConflictPolicy) (added, merged int, err error) {
+	for _, record := range records {
+		lessonID, err := s.importLesson(ctx, tx, record.FilePath, record.Sheet, record.LessonUTC)
+
+		if err != nil {
+			return 0, 0, err
+		}
+
+		stored, found, err := loadPhraseProgress(ctx, tx, lessonID, record.Key())
+
+		if err != nil {
+			return 0, 0, err
+		}
+
+		stats := record.Statistics
+
+		if found {
+			stats = mergeStatistics(stored, record.Statistics, policy)
+
+			merged++
+		} else {
+			added++
+		}
+
+		err = upsertPhraseProgress(ctx, tx, lessonID, record.Key(), stats)
+
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return added, merged, nil
+}
+
+// Returns ID of the lesson (adds it if it isn't stored). The last usage date
+// of the lesson becomes the latest of the stored and the given ones (now if it is unknown).
+func (s *File) importLesson(ctx context.Context, tx *sql.Tx, excelFilePath, sheet string, lastUTC time.Time) (int64, error) {
+	if lastUTC.IsZero() {
+		lastUTC = time.Now()
+	}
+
+	lastInSQLiteFormat := lastUTC.UTC().Format(SQLITE_TIME_FORMAT)
+
+	lessonID, err := s.getExcelLessonID(ctx, tx, excelFilePath, sheet)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		requestText := `
+			INSERT INTO EXCEL_LESSONS (PROFILE, DATE_UTC, FILE_PATH, FILE_SHEET, MODE) VALUES
+			(?, ?, ?, ?, ?)
+		`
+
+		requestRes, err := tx.ExecContext(ctx, requestText, s.profileID, lastInSQLiteFormat, excelFilePath, sheet, app.LessonModeLern)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return requestRes.LastInsertId()
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	requestText := `
+		UPDATE EXCEL_LESSONS
+		SET DATE_UTC = MAX(DATE_UTC, ?)
+		WHERE ID = ?
+	`
+
+	_, err = tx.ExecContext(ctx, requestText, lastInSQLiteFormat, lessonID)
+
+	return lessonID, err
+}
+
+func loadPhraseProgress(ctx context.Context, tx *sql.Tx, lessonID int64, key app.PhraseKey) (stats advanced.PhraseLearningStatistics, found bool, err error) {
+	requestText := `
+		SELECT
+			COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED
+		FROM LESSONS_PROGRESS
+		WHERE EXCEL_LESSON = ? AND PHRASE = ? AND OCCURRENCE = ?
+	`
+
+	err = tx.QueryRowContext(ctx, requestText, lessonID, key.Phrase, key.Occurrence).Scan(
+		&stats.CountGuessedOOS,
+		&stats.CountFailedOOS,
+		&stats.CountAnsweredTM,
+		&stats.CountFailedTM,
+		&stats.CountGuessedOOSInverted,
+		&stats.CountFailedOOSInverted,
+		&stats.CountAnsweredTMInverted,
+		&stats.CountFailedTMInverted,
+		&stats.CountAlmostTM,
+		&stats.CountAlmostTMInverted,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return stats, false, nil
+	}
+
+	return stats, err == nil, err
+}
+
+func upsertPhraseProgress(ctx context.Context, tx *sql.Tx, lessonID int64, key app.PhraseKey, stats advanced.PhraseLearningStatistics) error {
+	requestText := `
+		INSERT INTO LESSONS_PROGRESS
+		(
+			EXCEL_LESSON,
+			PHRASE,
+			OCCURRENCE,
+			COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED
+		)
+		VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (EXCEL_LESSON, PHRASE, OCCURRENCE) DO UPDATE SET
+			COUNT_GUESSED_OOS = excluded.COUNT_GUESSED_OOS,
+			COUNT_FAILED_OOS = excluded.COUNT_FAILED_OOS,
+			COUNT_ANSWERED_TM = excluded.COUNT_ANSWERED_TM,
+			COUNT_FAILED_TM = excluded.COUNT_FAILED_TM,
+			COUNT_GUESSED_OOS_INVERTED = excluded.COUNT_GUESSED_OOS_INVERTED,
+			COUNT_FAILED_OOS_INVERTED = excluded.COUNT_FAILED_OOS_INVERTED,
+			COUNT_ANSWERED_TM_INVERTED = excluded.COUNT_ANSWERED_TM_INVERTED,
+			COUNT_FAILED_TM_INVERTED = excluded.COUNT_FAILED_TM_INVERTED,
+			COUNT_ALMOST_TM = excluded.COUNT_ALMOST_TM,
+			COUNT_ALMOST_TM_INVERTED = excluded.COUNT_ALMOST_TM_INVERTED
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		requestText,
+		lessonID,
+		key.Phrase,
+		key.Occurrence,
+		stats.CountGuessedOOS,
+		stats.CountFailedOOS,
+		stats.CountAnsweredTM,
+		stats.CountFailedTM,
+		stats.CountGuessedOOSInverted,
+		stats.CountFailedOOSInverted,
+		stats.CountAnsweredTMInverted,
+		stats.CountFailedTMInverted,
+		stats.CountAlmostTM,
+		stats.CountAlmostTMInverted,
+	)
+
+	return err
+}
+
+func mergeStatistics(stored, imported advanced.PhraseLearningStatistics, policy ConflictPolicy) advanced.PhraseLearningStatistics {
+	switch policy {
+	case ConflictSum:
+		return stored.Combine(&imported, func(a, b uint32) uint32 { return a + b })
+	case ConflictOverwrite:
+		return imported
+	}
+
+	return stored.Combine(&imported, func(a, b uint32) uint32 { return max(a, b) })
+}
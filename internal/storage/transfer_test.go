@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"vocabulary/internal/app"
+	"vocabulary/internal/app/advanced"
+)
+
+func TestImportProgress(t *testing.T) {
+	file, err := Open(t.Context(), filepath.Join(t.TempDir(), "storage"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	err = file.SaveLessonProgress(
+		t.Context(),
+		"words.xlsx",
+		"Unit 1",
+		map[app.PhraseKey]advanced.PhraseLearningStatistics{
+			{Phrase: "car"}: {CountGuessedOOS: 2, CountFailedOOS: 1},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := file.ExportProgress(t.Context(), "words.xlsx", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 || records[0].Phrase != "car" || records[0].Statistics.CountGuessedOOS != 2 || records[0].LessonUTC.IsZero() {
+		t.Fatal("unexpected exported records:", records)
+	}
+
+	imported := []ProgressRecord{
+		{FilePath: "words.xlsx", Sheet: "Unit 1", Phrase: "car", Statistics: advanced.PhraseLearningStatistics{CountGuessedOOS: 1, CountFailedOOS: 3}},
+		{FilePath: "words.xlsx", Sheet: "Unit 2", Phrase: "dog", Statistics: advanced.PhraseLearningStatistics{CountAnsweredTM: 1}},
+	}
+
+	for _, testCase := range []struct {
+		policy   ConflictPolicy
+		expected advanced.PhraseLearningStatistics
+	}{
+		{ConflictKeepMax, advanced.PhraseLearningStatistics{CountGuessedOOS: 2, CountFailedOOS: 3}},
+		{ConflictSum, advanced.PhraseLearningStatistics{CountGuessedOOS: 3, CountFailedOOS: 6}},
+		{ConflictOverwrite, advanced.PhraseLearningStatistics{CountGuessedOOS: 1, CountFailedOOS: 3}},
+	} {
+		_, _, err = file.ImportProgress(t.Context(), imported, testCase.policy)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := file.LoadLessonProgress(t.Context(), "words.xlsx", "Unit 1")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if loaded[app.PhraseKey{Phrase: "car"}] != testCase.expected {
+			t.Fatalf("policy %d: expected %+v, got %+v", testCase.policy, testCase.expected, loaded)
+		}
+	}
+
+	loaded, err := file.LoadLessonProgress(t.Context(), "words.xlsx", "Unit 2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded[app.PhraseKey{Phrase: "dog"}].CountAnsweredTM != 1 {
+		t.Fatal("progress of unknown lesson should be added:", loaded)
+	}
+}
@@ -0,0 +1,7 @@
+package transfer
+
+// Formats of progress files.
+const (
+	FORMAT_JSON = "json"
+	FORMAT_CSV  = "csv"
+)
@@ -0,0 +1,330 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"vocabulary/internal/app/advanced"
+	"vocabulary/internal/storage"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format of progress file")
+
+	ErrMissingColumn = errors.New("missing column")
+
+	ErrEmptyField = errors.New("empty field")
+)
+
+// The same fields are used for JSON objects and CSV columns.
+// Times are in RFC 3339 format, unknown ones are empty.
+type record struct {
+	FilePath      string `json:"file"`
+	Sheet         string `json:"sheet"`
+	LessonUTC     string `json:"lesson_utc"`
+	Phrase        string `json:"phrase"`
+	Occurrence    uint32 `json:"occurrence"`
+	LastAnswerUTC string `json:"last_answer_utc,omitempty"`
+
+	CountGuessedOOS         uint32 `json:"count_guessed_oos"`
+	CountFailedOOS          uint32 `json:"count_failed_oos"`
+	CountAnsweredTM         uint32 `json:"count_answered_tm"`
+	CountFailedTM           uint32 `json:"count_failed_tm"`
+	CountGuessedOOSInverted uint32 `json:"count_guessed_oos_inverted"`
+	CountFailedOOSInverted  uint32 `json:"count_failed_oos_inverted"`
+	CountAnsweredTMInverted uint32 `json:"count_answered_tm_inverted"`
+	CountFailedTMInverted   uint32 `json:"count_failed_tm_inverted"`
+	CountAlmostTM           uint32 `json:"count_almost_tm"`
+	CountAlmostTMInverted   uint32 `json:"count_almost_tm_inverted"`
+}
+
+var csvHeader = []string{
+	"file",
+	"sheet",
+	"lesson_utc",
+	"phrase",
+	"occurrence",
+	"last_answer_utc",
+	"count_guessed_oos",
+	"count_failed_oos",
+	"count_answered_tm",
+	"count_failed_tm",
+	"count_guessed_oos_inverted",
+	"count_failed_oos_inverted",
+	"count_answered_tm_inverted",
+	"count_failed_tm_inverted",
+	"count_almost_tm",
+	"count_almost_tm_inverted",
+}
+
+// Fields which can't be empty in imported records (CSV files should contain their' columns).
+var requiredFields = []string{"file", "sheet", "phrase"}
+
+// Returns the format by the extension of the file (FORMAT_JSON if it is unknown).
+func FormatOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), "."+FORMAT_CSV) {
+		return FORMAT_CSV
+	}
+
+	return FORMAT_JSON
+}
+
+func Write(w io.Writer, format string, records []storage.ProgressRecord) error {
+	converted := make([]record, len(records))
+
+	for i := range records {
+		converted[i] = toRecord(&records[i])
+	}
+
+	switch format {
+	case FORMAT_JSON:
+		encoder := json.NewEncoder(w)
+
+		encoder.SetIndent("", "\t")
+
+		return encoder.Encode(converted)
+	case FORMAT_CSV:
+		return writeCSV(w, converted)
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+func Read(r io.Reader, format string) ([]storage.ProgressRecord, error) {
+	var (
+		converted []record
+		err       error
+	)
+
+	switch format {
+	case FORMAT_JSON:
+		err = json.NewDecoder(r).Decode(&converted)
+	case FORMAT_CSV:
+		converted, err = readCSV(r)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]storage.ProgressRecord, len(converted))
+
+	for i := range converted {
+		res[i], err = fromRecord(&converted[i])
+
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+
+	return res, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+func toRecord(r *storage.ProgressRecord) record {
+	return record{
+		FilePath:      r.FilePath,
+		Sheet:         r.Sheet,
+		LessonUTC:     formatTime(r.LessonUTC),
+		Phrase:        r.Phrase,
+		Occurrence:    r.Occurrence,
+		LastAnswerUTC: formatTime(r.LastAnswerUTC),
+
+		CountGuessedOOS:         r.Statistics.CountGuessedOOS,
+		CountFailedOOS:          r.Statistics.CountFailedOOS,
+		CountAnsweredTM:         r.Statistics.CountAnsweredTM,
+		CountFailedTM:           r.Statistics.CountFailedTM,
+		CountGuessedOOSInverted: r.Statistics.CountGuessedOOSInverted,
+		CountFailedOOSInverted:  r.Statistics.CountFailedOOSInverted,
+		CountAnsweredTMInverted: r.Statistics.CountAnsweredTMInverted,
+		CountFailedTMInverted:   r.Statistics.CountFailedTMInverted,
+		CountAlmostTM:           r.Statistics.CountAlmostTM,
+		CountAlmostTMInverted:   r.Statistics.CountAlmostTMInverted,
+	}
+}
+
+func fromRecord(r *record) (storage.ProgressRecord, error) {
+	columns := r.columns()
+
+	for _, required := range requiredFields {
+		if *columns[required].(*string) == "" {
+			return storage.ProgressRecord{}, fmt.Errorf("%w %q", ErrEmptyField, required)
+		}
+	}
+
+	lessonUTC, err := parseTime(r.LessonUTC)
+
+	if err != nil {
+		return storage.ProgressRecord{}, err
+	}
+
+	lastAnswerUTC, err := parseTime(r.LastAnswerUTC)
+
+	if err != nil {
+		return storage.ProgressRecord{}, err
+	}
+
+	return storage.ProgressRecord{
+		FilePath:      r.FilePath,
+		Sheet:         r.Sheet,
+		LessonUTC:     lessonUTC,
+		Phrase:        r.Phrase,
+		Occurrence:    r.Occurrence,
+		LastAnswerUTC: lastAnswerUTC,
+		Statistics: advanced.PhraseLearningStatistics{
+			CountGuessedOOS:         r.CountGuessedOOS,
+			CountFailedOOS:          r.CountFailedOOS,
+			CountAnsweredTM:         r.CountAnsweredTM,
+			CountFailedTM:           r.CountFailedTM,
+			CountGuessedOOSInverted: r.CountGuessedOOSInverted,
+			CountFailedOOSInverted:  r.CountFailedOOSInverted,
+			CountAnsweredTMInverted: r.CountAnsweredTMInverted,
+			CountFailedTMInverted:   r.CountFailedTMInverted,
+			CountAlmostTM:           r.CountAlmostTM,
+			CountAlmostTMInverted:   r.CountAlmostTMInverted,
+		},
+	}, nil
+}
+
+// Returns pointers to fields of the record (*string or *uint32) by names of CSV columns.
+func (r *record) columns() map[string]any {
+	return map[string]any{
+		"file":                       &r.FilePath,
+		"sheet":                      &r.Sheet,
+		"lesson_utc":                 &r.LessonUTC,
+		"phrase":                     &r.Phrase,
+		"occurrence":                 &r.Occurrence,
+		"last_answer_utc":            &r.LastAnswerUTC,
+		"count_guessed_oos":          &r.CountGuessedOOS,
+		"count_failed_oos":           &r.CountFailedOOS,
+		"count_answered_tm":          &r.CountAnsweredTM,
+		"count_failed_tm":            &r.CountFailedTM,
+		"count_guessed_oos_inverted": &r.CountGuessedOOSInverted,
+		"count_failed_oos_inverted":  &r.CountFailedOOSInverted,
+		"count_answered_tm_inverted": &r.CountAnsweredTMInverted,
+		"count_failed_tm_inverted":   &r.CountFailedTMInverted,
+		"count_almost_tm":            &r.CountAlmostTM,
+		"count_almost_tm_inverted":   &r.CountAlmostTMInverted,
+	}
+}
+
+func writeCSV(w io.Writer, records []record) error {
+	csvWriter := csv.NewWriter(w)
+
+	err := csvWriter.Write(csvHeader)
+
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(csvHeader))
+
+	for i := range records {
+		columns := records[i].columns()
+
+		for j, name := range csvHeader {
+			switch field := columns[name].(type) {
+			case *string:
+				row[j] = *field
+			case *uint32:
+				row[j] = strconv.FormatUint(uint64(*field), 10)
+			}
+		}
+
+		err = csvWriter.Write(row)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// Columns are matched by names in the header, so they can be reordered or omitted
+// (omitted counters are zero), unknown columns are ignored.
+func readCSV(r io.Reader) ([]record, error) {
+	csvReader := csv.NewReader(r)
+
+	header, err := csvReader.Read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, required := range requiredFields {
+		if !slices.Contains(header, required) {
+			return nil, fmt.Errorf("%w %q", ErrMissingColumn, required)
+		}
+	}
+
+	res := []record{}
+
+	for {
+		row, err := csvReader.Read()
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			rec     record
+			columns = rec.columns()
+		)
+
+		for i, name := range header {
+			switch field := columns[name].(type) {
+			case *string:
+				*field = row[i]
+			case *uint32:
+				if row[i] == "" {
+					continue
+				}
+
+				value, err := strconv.ParseUint(row[i], 10, 32)
+
+				if err != nil {
+					line, _ := csvReader.FieldPos(i)
+
+					return nil, fmt.Errorf("line %d, column %q: %w", line, name, err)
+				}
+
+				*field = uint32(value)
+			}
+		}
+
+		res = append(res, rec)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"vocabulary/internal/app/advanced"
+	"vocabulary/internal/storage"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := []storage.ProgressRecord{
+		{
+			FilePath:      "/home/user/words.xlsx",
+			Sheet:         "Unit 1",
+			LessonUTC:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Phrase:        "bank, \"river\"",
+			Occurrence:    1,
+			LastAnswerUTC: time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC),
+			Statistics:    advanced.PhraseLearningStatistics{CountGuessedOOS: 1, CountFailedTMInverted: 2, CountAlmostTM: 3},
+		},
+		{
+			FilePath:  "/home/user/words.xlsx",
+			Sheet:     "Unit 2",
+			LessonUTC: time.Date(2025, 1, 3, 3, 4, 5, 0, time.UTC),
+			Phrase:    "car",
+		},
+	}
+
+	for _, format := range []string{FORMAT_JSON, FORMAT_CSV} {
+		buffer := &bytes.Buffer{}
+
+		err := Write(buffer, format, records)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		read, err := Read(buffer, format)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(read, records) {
+			t.Fatalf("%s: expected %+v, got %+v", format, records, read)
+		}
+	}
+}
+
+func TestPartialCSV(t *testing.T) {
+	records, err := Read(strings.NewReader("phrase,extra,sheet,file,count_failed_oos\ncar,x,Unit 1,words.xlsx,4\n"), FORMAT_CSV)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []storage.ProgressRecord{
+		{FilePath: "words.xlsx", Sheet: "Unit 1", Phrase: "car", Statistics: advanced.PhraseLearningStatistics{CountFailedOOS: 4}},
+	}
+
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatal("unexpected records:", records)
+	}
+
+	_, err = Read(strings.NewReader("phrase,sheet\ncar,Unit 1\n"), FORMAT_CSV)
+
+	if err == nil {
+		t.Fatal("file without a required column shouldn't be read")
+	}
+}
+
+func TestEmptyRequiredFields(t *testing.T) {
+	for _, testCase := range []struct {
+		format string
+		text   string
+	}{
+		{FORMAT_JSON, `[{"file": "words.xlsx", "sheet": "Unit 1", "phrase": "car"}, {"file": "words.xlsx", "sheet": "Unit 1"}]`},
+		{FORMAT_JSON, `[{"sheet": "Unit 1", "phrase": "car"}]`},
+		{FORMAT_CSV, "file,sheet,phrase\nwords.xlsx,,car\n"},
+		{FORMAT_CSV, "file,sheet,phrase\nwords.xlsx,Unit 1,\n"},
+	} {
+		_, err := Read(strings.NewReader(testCase.text), testCase.format)
+
+		if !errors.Is(err, ErrEmptyField) {
+			t.Fatalf("%s %q: ErrEmptyField expected, got: %v", testCase.format, testCase.text, err)
+		}
+	}
+}
@@ -13,4 +13,10 @@ const (
 	//The delay before switching to the next task after successfull solution of current one.
 	//Souldn't be bigger than TIME_BEFORE_SHOWING_WAITING_SCREEN.
 	TIME_TO_DEMONSTRATE_RIGHT_ANSWER = time.Millisecond * 750
+
+	//Max count of options in one row of the grid of options in choice tasks.
+	MAX_OPTIONS_IN_ROW = 4
+
+	//Max count of pairs of phrases in the list of commonly confused ones.
+	COMMONLY_CONFUSED_COUNT = 50
 )
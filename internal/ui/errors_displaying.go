@@ -33,6 +33,14 @@ func identifyTranslateErr(err error) string {
 		return lang.L("Task pre-loading error")
 	}
 
+	if errors.Is(err, app.ErrNoPhrasesToRepeat) {
+		return lang.L("No phrases to repeat now")
+	}
+
+	if errors.Is(err, app.ErrNoTasksAvailable) {
+		return lang.L("No tasks available")
+	}
+
 	return ""
 }
 
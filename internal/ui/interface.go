@@ -15,6 +15,21 @@ type Application interface {
 	ChooseTopic(string)
 	Topic() string
 
+	//Returns confusions of phrases of the current topic, the most frequent first.
+	CommonlyConfused() ([]app.Confusion, error)
+
+	//Returns a lesson whose progress probably belongs to the current topic
+	//(for example, the file was moved or renamed), if the topic has no progress.
+	RelinkCandidate() (filePath, sheet string, found bool)
+	//Moves progress of the lesson to the current topic.
+	Relink(filePath, sheet string) error
+
+	//Profiles of learners, the most recently selected first.
+	Profiles() []string
+	Profile() string
+	//Selects the profile (adds it if it is new) and opens its' last lesson.
+	SelectProfile(name string) error
+
 	ProgressRecoveryIsAvailable() bool
 	BeginLesson(recoverProgress bool) (app.Lesson, error)
 }
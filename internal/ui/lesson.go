@@ -82,14 +82,21 @@ func (m *lessonMenu) phraseTranslatedManually() {
 	}
 
 	var (
-		t       = m.task.(app.TranslateManually)
-		isRight bool
-		err     error
+		t                = m.task.(app.TranslateManually)
+		verdict          app.Verdict
+		rightTranslation string
+		err              error
 	)
 
 	m.async(
 		func(ctx context.Context) {
-			isRight, err = t.Right(m.ctx, m.translation.Text)
+			verdict, err = t.Right(m.ctx, m.translation.Text)
+
+			//The right translation is shown instead of almost correct one
+			//to let user see the typos.
+			if err == nil && verdict == app.VerdictAlmostCorrect {
+				rightTranslation = t.RightTranslation()
+			}
 		},
 		func() {
 			if err != nil {
@@ -100,7 +107,8 @@ func (m *lessonMenu) phraseTranslatedManually() {
 
 			var newImportance widget.Importance
 
-			if isRight {
+			switch verdict {
+			case app.VerdictCorrect:
 				newImportance = widget.SuccessImportance
 
 				pause := TIME_TO_DEMONSTRATE_RIGHT_ANSWER
@@ -110,7 +118,15 @@ func (m *lessonMenu) phraseTranslatedManually() {
 				}
 
 				m.next(pause)
-			} else {
+			case app.VerdictAlmostCorrect:
+				newImportance = widget.WarningImportance
+
+				m.translation.OnChanged = nil
+				m.translation.SetText(rightTranslation)
+				m.translation.OnChanged = m.translationChanged
+
+				m.translateManuallyRightAnswer = rightTranslation
+			default:
 				newImportance = widget.DangerImportance
 			}
 
@@ -120,6 +136,11 @@ func (m *lessonMenu) phraseTranslatedManually() {
 	)
 }
 
+func (m *lessonMenu) translationChanged(string) {
+	m.checkTranslation.Importance = widget.MediumImportance
+	m.checkTranslation.Refresh()
+}
+
 func (m *lessonMenu) translationOptionChosen(option int) {
 	if m.ignoringUserActions() {
 		return
@@ -221,6 +242,15 @@ func (m *lessonMenu) async(async func(context.Context), inUIGouroutine func()) {
 	)
 }
 
+// Returns the count of columns of the grid of options: options are placed
+// in the minimal count of rows with not more than MAX_OPTIONS_IN_ROW options
+// and the rows are filled evenly.
+func optionsGridColumns(optionsCount int) int {
+	rows := (optionsCount + MAX_OPTIONS_IN_ROW - 1) / MAX_OPTIONS_IN_ROW
+
+	return max((optionsCount+rows-1)/max(rows, 1), 1)
+}
+
 func (m *lessonMenu) showTask(newTask app.PhraseLearningTask) {
 	var (
 		content fyne.CanvasObject
@@ -301,7 +331,7 @@ func (m *lessonMenu) showTask(newTask app.PhraseLearningTask) {
 			layout.NewSpacer(),
 			container.NewHBox(
 				layout.NewSpacer(),
-				container.NewGridWithColumns(4, optionsCO...),
+				container.NewGridWithColumns(optionsGridColumns(len(optionsCO)), optionsCO...),
 				layout.NewSpacer(),
 			),
 			layout.NewSpacer(),
@@ -472,10 +502,7 @@ func openLesson(ctx context.Context, wg *sync.WaitGroup, mainWindow fyne.Window,
 
 	m.checkTranslation.OnTapped = m.phraseTranslatedManually
 
-	m.translation.OnChanged = func(s string) {
-		m.checkTranslation.Importance = widget.MediumImportance
-		m.checkTranslation.Refresh()
-	}
+	m.translation.OnChanged = m.translationChanged
 
 	m.toMainMenu.OnTapped = func() {
 		cancelMenuContext()
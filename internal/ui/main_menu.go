@@ -2,6 +2,9 @@ package ui
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"vocabulary/internal/app"
 
@@ -17,10 +20,12 @@ import (
 type mainMenu struct {
 	menu
 
-	filePathEntry  *widget.Entry
-	learnButton    *widget.Button
-	topicSelection *widget.Select
-	modeSelection  *widget.Select
+	filePathEntry    *widget.Entry
+	learnButton      *widget.Button
+	confusedButton   *widget.Button
+	topicSelection   *widget.Select
+	modeSelection    *widget.Select
+	profileSelection *widget.Select
 }
 
 func (m *mainMenu) topicChanged(topic string) {
@@ -64,6 +69,55 @@ func (m *mainMenu) showError(err error) {
 }
 
 func (m *mainMenu) learnButtonPressed() {
+	filePath, sheet, found := m.app.RelinkCandidate()
+
+	if !found {
+		m.offerProgressRecovery()
+
+		return
+	}
+
+	dialogTextLabel := widget.NewLabel(
+		lang.L("Progress of a lesson with the same phrases was found") + ":\n" + filePath + " (" + sheet + ")\n" + lang.L("Was the file moved? Move its progress to this one?"),
+	)
+
+	dialogTextLabel.Alignment = fyne.TextAlignCenter
+
+	dlg := dialog.NewCustom(lang.L("Progress of moved file"), "OK", dialogTextLabel, m.mainWindow)
+
+	dlg.SetButtons(
+		[]fyne.CanvasObject{
+			widget.NewButton(
+				lang.L("Yes"),
+				func() {
+					dlg.Dismiss()
+
+					err := m.app.Relink(filePath, sheet)
+
+					if err != nil {
+						m.showError(err)
+
+						return
+					}
+
+					m.offerProgressRecovery()
+				},
+			),
+			widget.NewButton(
+				lang.L("No"),
+				func() {
+					dlg.Dismiss()
+
+					m.offerProgressRecovery()
+				},
+			),
+		},
+	)
+
+	dlg.Show()
+}
+
+func (m *mainMenu) offerProgressRecovery() {
 	if m.app.ProgressRecoveryIsAvailable() {
 		dialogTextLabel := widget.NewLabel(lang.L("Recover progress?"))
 
@@ -98,6 +152,47 @@ func (m *mainMenu) learnButtonPressed() {
 	}
 }
 
+// Shows phrases which the user confuses most often in the current topic.
+func (m *mainMenu) confusedButtonPressed() {
+	confusions, err := m.app.CommonlyConfused()
+
+	if err != nil {
+		m.showError(err)
+
+		return
+	}
+
+	if len(confusions) > COMMONLY_CONFUSED_COUNT {
+		confusions = confusions[:COMMONLY_CONFUSED_COUNT]
+	}
+
+	var content fyne.CanvasObject
+
+	if len(confusions) <= 0 {
+		content = widget.NewLabel(lang.L("No confusions yet"))
+	} else {
+		content = widget.NewList(
+			func() int {
+				return len(confusions)
+			},
+			func() fyne.CanvasObject {
+				return widget.NewLabel("")
+			},
+			func(i widget.ListItemID, item fyne.CanvasObject) {
+				item.(*widget.Label).SetText(
+					fmt.Sprintf("%s ≠ %s (%d)", confusions[i].Phrase, confusions[i].ConfusedWith, confusions[i].Count),
+				)
+			},
+		)
+	}
+
+	dlg := dialog.NewCustom(lang.L("Commonly confused"), lang.L("OK"), content, m.mainWindow)
+
+	dlg.Resize(fyne.NewSize(m.mainWindow.Canvas().Size().Width*0.8, m.mainWindow.Canvas().Size().Height*0.8))
+
+	dlg.Show()
+}
+
 func (m *mainMenu) beginLesson(recoverProgress bool) {
 	lesson, err := m.app.BeginLesson(recoverProgress)
 
@@ -116,6 +211,58 @@ func (m *mainMenu) modeSelected(string) {
 		m.app.SetLessonMode(app.LessonModeLern)
 	case 1:
 		m.app.SetLessonMode(app.LessonModeLeanSpellingOnly)
+	case 2:
+		m.app.SetLessonMode(app.LessonModeSpacedRepetition)
+	}
+
+	m.update()
+}
+
+// The last option of the profile selection adds a new profile.
+func (m *mainMenu) profileSelected(string) {
+	if m.profileSelection.SelectedIndex() == len(m.profileSelection.Options)-1 {
+		m.newProfileRequested()
+
+		return
+	}
+
+	m.selectProfile(m.profileSelection.Selected)
+}
+
+func (m *mainMenu) newProfileRequested() {
+	nameEntry := widget.NewEntry()
+
+	nameEntry.Validator = func(name string) error {
+		if strings.TrimSpace(name) == "" {
+			return errors.New(lang.L("Name of profile is empty"))
+		}
+
+		return nil
+	}
+
+	dialog.ShowForm(
+		lang.L("New profile"),
+		lang.L("OK"),
+		lang.L("Cancel"),
+		[]*widget.FormItem{widget.NewFormItem(lang.L("Name"), nameEntry)},
+		func(confirmed bool) {
+			if confirmed {
+				m.selectProfile(nameEntry.Text)
+			} else {
+				m.update()
+			}
+		},
+		m.mainWindow,
+	)
+}
+
+func (m *mainMenu) selectProfile(name string) {
+	if name != m.app.Profile() {
+		err := m.app.SelectProfile(name)
+
+		if err != nil {
+			m.showError(err)
+		}
 	}
 
 	m.update()
@@ -126,6 +273,10 @@ func (m *mainMenu) update() {
 	m.topicSelection.OnChanged = nil
 	m.filePathEntry.OnChanged = nil
 	m.modeSelection.OnChanged = nil
+	m.profileSelection.OnChanged = nil
+
+	m.profileSelection.SetOptions(append(m.app.Profiles(), lang.L("New profile...")))
+	m.profileSelection.SetSelected(m.app.Profile())
 
 	path := m.app.FilePath()
 
@@ -147,8 +298,10 @@ func (m *mainMenu) update() {
 
 	if m.topicSelection.SelectedIndex() >= 0 {
 		m.learnButton.Enable()
+		m.confusedButton.Enable()
 	} else {
 		m.learnButton.Disable()
+		m.confusedButton.Disable()
 	}
 
 	switch m.app.GetLessonMode() {
@@ -156,12 +309,15 @@ func (m *mainMenu) update() {
 		m.modeSelection.SetSelectedIndex(0)
 	case app.LessonModeLeanSpellingOnly:
 		m.modeSelection.SetSelectedIndex(1)
+	case app.LessonModeSpacedRepetition:
+		m.modeSelection.SetSelectedIndex(2)
 	}
 
 	m.learnButton.OnTapped = m.learnButtonPressed
 	m.topicSelection.OnChanged = m.topicChanged
 	m.filePathEntry.OnChanged = m.filePathChanged
 	m.modeSelection.OnChanged = m.modeSelected
+	m.profileSelection.OnChanged = m.profileSelected
 }
 
 // Opens a menu for choice an excel file and its' sheet.
@@ -173,15 +329,18 @@ func openMainMenu(ctx context.Context, wg *sync.WaitGroup, mainWindow fyne.Windo
 			ctx:        ctx,
 			wg:         wg,
 		},
-		filePathEntry:  widget.NewEntry(),
-		learnButton:    widget.NewButton(lang.L("Begin lesson"), nil),
-		topicSelection: widget.NewSelect([]string{}, nil),
-		modeSelection:  widget.NewSelect([]string{lang.L("Learn"), lang.L("Spelling only")}, nil),
+		filePathEntry:    widget.NewEntry(),
+		learnButton:      widget.NewButton(lang.L("Begin lesson"), nil),
+		confusedButton:   widget.NewButton(lang.L("Commonly confused"), nil),
+		topicSelection:   widget.NewSelect([]string{}, nil),
+		modeSelection:    widget.NewSelect([]string{lang.L("Learn"), lang.L("Spelling only"), lang.L("Spaced repetition")}, nil),
+		profileSelection: widget.NewSelect([]string{}, nil),
 	}
 
 	menu.learnButton.Importance = widget.HighImportance
 
 	menu.learnButton.OnTapped = menu.learnButtonPressed
+	menu.confusedButton.OnTapped = menu.confusedButtonPressed
 	menu.topicSelection.OnChanged = menu.topicChanged
 	menu.filePathEntry.OnChanged = menu.filePathChanged
 
@@ -198,6 +357,7 @@ func openMainMenu(ctx context.Context, wg *sync.WaitGroup, mainWindow fyne.Windo
 			container.NewHBox(
 				menu.learnButton,
 				layout.NewSpacer(),
+				menu.confusedButton,
 			),
 			nil,
 			nil,
@@ -208,6 +368,10 @@ func openMainMenu(ctx context.Context, wg *sync.WaitGroup, mainWindow fyne.Windo
 				layout.NewSpacer(),
 				container.New(
 					layout.NewFormLayout(),
+					widget.NewLabel(
+						lang.L("Profile")+":",
+					),
+					menu.profileSelection,
 					widget.NewLabel(
 						lang.L("File")+":",
 					),